@@ -0,0 +1,246 @@
+package capo
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bindingTags are the struct tags that bind the request parameters into the
+// entity fields.
+var bindingTags = []string{"path", "query", "header", "cookie"}
+
+// FieldError is an error related to an entity field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// FieldErrors is the list of errors found in the entity fields.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Bind takes the information in the request body, if it exists, and unmarshal
+// the data in the entity provided. Then, it sets the entity fields tagged with
+// "path", "query", "header" and "cookie" with the request parameters. e.g.
+//
+//	type Input struct {
+//		ID     int    `path:"id"`
+//		Limit  int    `query:"limit"`
+//		Tenant string `header:"X-Tenant"`
+//		Name   string `json:"name"`
+//	}
+//
+// It returns a bad request error with "ErrEmptyBody" if the body is empty and
+// the entity does not have any field to bind.
+func (ctx *Context) Bind(entity any) error {
+	err := ctx.Read(entity)
+	empty := errors.Is(err, ErrEmptyBody)
+	if err != nil && !empty {
+		return err
+	}
+
+	bound, err := bindParams(ctx.r, entity)
+	if err != nil {
+		return err
+	}
+
+	if empty && !bound {
+		return NewServerError(BadRequestErrorCode, ErrEmptyBody)
+	}
+
+	return nil
+}
+
+// bindParams sets the entity fields with the request parameters. It returns
+// true if the entity has fields to bind.
+func bindParams(r *http.Request, entity any) (bool, error) {
+	v := reflect.ValueOf(entity)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return false, nil
+	}
+
+	errs := FieldErrors{}
+	bound := bindStruct(r, v.Elem(), &errs)
+	if len(errs) > 0 {
+		return bound, NewServerError(InvalidParamErrorCode, errs).WithDetails(errs)
+	}
+
+	return bound, nil
+}
+
+// bindStruct sets the struct fields with the request parameters. The errors
+// found are included in the list provided.
+func bindStruct(r *http.Request, v reflect.Value, errs *FieldErrors) bool {
+	bound := false
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		// Bind the embedded structs fields.
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if bindStruct(r, fieldValue, errs) {
+				bound = true
+			}
+			continue
+		}
+
+		for _, tag := range bindingTags {
+			name, ok := field.Tag.Lookup(tag)
+			if !ok || name == "" || name == "-" {
+				continue
+			}
+
+			bound = true
+
+			values := paramValues(r, tag, name)
+			if len(values) == 0 {
+				continue
+			}
+
+			err := setValues(fieldValue, values)
+			if err != nil {
+				*errs = append(*errs, &FieldError{
+					Field:   name,
+					Message: fmt.Sprintf("invalid %s parameter :: %s", tag, err.Error()),
+				})
+			}
+		}
+	}
+
+	return bound
+}
+
+// paramValues returns the request values for the parameter provided.
+func paramValues(r *http.Request, source string, name string) []string {
+	switch source {
+	case "path":
+		if value, ok := mux.Vars(r)[name]; ok {
+			return []string{value}
+		}
+	case "query":
+		return r.URL.Query()[name]
+	case "header":
+		return r.Header.Values(name)
+	case "cookie":
+		if cookie, err := r.Cookie(name); err == nil {
+			return []string{cookie.Value}
+		}
+	}
+
+	return nil
+}
+
+// setValues sets the values provided in the field. Only the slices take all the
+// values, the rest of types take the first one.
+func setValues(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Slice && !reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		result := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			err := setValue(result.Index(i), value)
+			if err != nil {
+				return err
+			}
+		}
+
+		v.Set(result)
+		return nil
+	}
+
+	return setValue(v, values[0])
+}
+
+// setValue converts the string provided into the field type and sets it.
+func setValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		err := setValue(elem.Elem(), value)
+		if err != nil {
+			return err
+		}
+
+		v.Set(elem)
+		return nil
+	}
+
+	// Use the type unmarshaler if it exists (e.g. "time.Time").
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package capo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/client"
+)
+
+type TestBindData struct {
+	ID        uuid.UUID `path:"id"`
+	Limit     int       `query:"limit"`
+	Active    *bool     `query:"active"`
+	Since     time.Time `query:"since"`
+	Tags      []string  `query:"tag"`
+	Tenant    string    `header:"X-Tenant"`
+	Session   string    `cookie:"session"`
+	Message   string    `json:"msg"`
+	Untouched string    `json:"-" query:"untouched"`
+}
+
+func TestContextBindsRequestParams(t *testing.T) {
+	serverHandler := New()
+
+	id := uuid.New()
+	since := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	serverHandler.Post("/items/{id}", func(ctx *Context) error {
+		data := &TestBindData{Untouched: "default"}
+		err := ctx.Bind(data)
+		require.NoError(t, err)
+
+		require.Equal(t, id, data.ID)
+		require.Equal(t, 10, data.Limit)
+		require.NotNil(t, data.Active)
+		require.True(t, *data.Active)
+		require.True(t, since.Equal(data.Since))
+		require.Equal(t, []string{"a", "b"}, data.Tags)
+		require.Equal(t, "tenant", data.Tenant)
+		require.Equal(t, "session-id", data.Session)
+		require.Equal(t, "body message", data.Message)
+		require.Equal(t, "default", data.Untouched)
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	err := client.NewRequest().
+		URL(s.URL+"?limit=10&active=true&since="+since.Format(time.RFC3339)+"&tag=a&tag=b").
+		RelativePath("items/"+id.String()).
+		Method(http.MethodPost).
+		AddHeader("X-Tenant", "tenant").
+		AddHeader("Cookie", "session=session-id").
+		Data(&TestBindData{Message: "body message"}).
+		Do(nil)
+	require.NoError(t, err)
+}
+
+func TestContextBindAggregatesFieldErrors(t *testing.T) {
+	serverHandler := New()
+
+	serverHandler.Get("/items/{id}", func(ctx *Context) error {
+		err := ctx.Bind(&TestBindData{})
+		var serverErr *ServerError
+		require.ErrorAs(t, err, &serverErr)
+		require.Equal(t, InvalidParamErrorCode, serverErr.Code)
+
+		fieldErrs, ok := serverErr.inner.(FieldErrors)
+		require.True(t, ok)
+		require.Len(t, fieldErrs, 3)
+		require.Equal(t, "id", fieldErrs[0].Field)
+		require.Equal(t, "limit", fieldErrs[1].Field)
+		require.Equal(t, "active", fieldErrs[2].Field)
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	err := client.NewRequest().URL(s.URL + "?limit=ten&active=maybe").RelativePath("items/invalid").Do(nil)
+	require.NoError(t, err)
+}
+
+func TestContextBindReturnsEmptyBodyWithoutParams(t *testing.T) {
+	serverHandler := New()
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		err := ctx.Bind(&TestData{})
+		require.ErrorIs(t, err, ErrEmptyBody)
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	err := client.NewRequest().URL(s.URL).Do(nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,243 @@
+// Package capotest provides utilities to test the capo servers and handlers
+// in process, without starting a http server.
+//
+//	s := capo.New()
+//	s.Post("/items", createItem)
+//
+//	item := &Item{}
+//	capotest.New(t, s).
+//		POST("/items").
+//		JSON(&Item{Name: "test"}).
+//		Expect().
+//		Status(http.StatusCreated).
+//		Header("Location", "/items/1").
+//		JSON(item)
+package capotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// Tester performs requests against a http handler, usually a "capo.Server".
+type Tester struct {
+	t       testing.TB
+	handler http.Handler
+	headers http.Header
+}
+
+// New creates a tester for the handler provided.
+func New(t testing.TB, handler http.Handler) *Tester {
+	return &Tester{
+		t:       t,
+		handler: handler,
+		headers: http.Header{},
+	}
+}
+
+// WithHeader sets a header that will be included in every request.
+func (tt *Tester) WithHeader(key string, value string) *Tester {
+	tt.headers.Set(key, value)
+	return tt
+}
+
+// Request returns a new request with the method and path provided.
+func (tt *Tester) Request(method string, path string) *Request {
+	return &Request{
+		tester: tt,
+		method: method,
+		path:   path,
+		query:  url.Values{},
+		header: tt.headers.Clone(),
+	}
+}
+
+// GET returns a new GET request to the path provided.
+func (tt *Tester) GET(path string) *Request {
+	return tt.Request(http.MethodGet, path)
+}
+
+// POST returns a new POST request to the path provided.
+func (tt *Tester) POST(path string) *Request {
+	return tt.Request(http.MethodPost, path)
+}
+
+// PUT returns a new PUT request to the path provided.
+func (tt *Tester) PUT(path string) *Request {
+	return tt.Request(http.MethodPut, path)
+}
+
+// PATCH returns a new PATCH request to the path provided.
+func (tt *Tester) PATCH(path string) *Request {
+	return tt.Request(http.MethodPatch, path)
+}
+
+// DELETE returns a new DELETE request to the path provided.
+func (tt *Tester) DELETE(path string) *Request {
+	return tt.Request(http.MethodDelete, path)
+}
+
+// HEAD returns a new HEAD request to the path provided.
+func (tt *Tester) HEAD(path string) *Request {
+	return tt.Request(http.MethodHead, path)
+}
+
+// OPTIONS returns a new OPTIONS request to the path provided.
+func (tt *Tester) OPTIONS(path string) *Request {
+	return tt.Request(http.MethodOptions, path)
+}
+
+// Request is a request performed by a tester.
+type Request struct {
+	tester *Tester
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// Header sets a request header.
+func (r *Request) Header(key string, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Query adds a query parameter to the request path.
+func (r *Request) Query(key string, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Body sets the raw request body and its content type.
+func (r *Request) Body(contentType string, body []byte) *Request {
+	r.header.Set("Content-Type", contentType)
+	r.body = body
+	return r
+}
+
+// JSON sets the entity provided as the request body in JSON format.
+func (r *Request) JSON(entity interface{}) *Request {
+	return r.Marshal(&marshaler.JSONMarshaler{}, entity)
+}
+
+// Marshal sets the entity provided as the request body with the marshaler
+// provided.
+func (r *Request) Marshal(m marshaler.Marshaler, entity interface{}) *Request {
+	r.tester.t.Helper()
+
+	body, err := m.Marshal(entity)
+	require.NoError(r.tester.t, err, "cannot marshal the request body")
+
+	return r.Body(m.ContentTypeHeader(), body)
+}
+
+// Expect performs the request and returns the response to check it.
+func (r *Request) Expect() *Response {
+	target := r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+
+	req := httptest.NewRequest(r.method, target, body)
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+
+	recorder := httptest.NewRecorder()
+	r.tester.handler.ServeHTTP(recorder, req)
+
+	return &Response{t: r.tester.t, recorder: recorder}
+}
+
+// Response is the response of a request performed by a tester. Its methods
+// fail the test if the response does not match the expectation.
+type Response struct {
+	t        testing.TB
+	recorder *httptest.ResponseRecorder
+}
+
+// Status checks the response status code.
+func (r *Response) Status(status int) *Response {
+	r.t.Helper()
+
+	require.Equal(r.t, status, r.recorder.Code, "unexpected response status, body: %s", r.recorder.Body.String())
+	return r
+}
+
+// Header checks the value of a response header.
+func (r *Response) Header(key string, value string) *Response {
+	r.t.Helper()
+
+	require.Equal(r.t, value, r.recorder.Header().Get(key), "unexpected %s response header", key)
+	return r
+}
+
+// JSON reads the response body in JSON format in the entity provided.
+func (r *Response) JSON(entity interface{}) *Response {
+	r.t.Helper()
+
+	require.NoError(r.t, json.Unmarshal(r.recorder.Body.Bytes(), entity), "invalid JSON response body: %s", r.recorder.Body.String())
+	return r
+}
+
+// JSONEq checks that the response body is the JSON provided.
+func (r *Response) JSONEq(expected string) *Response {
+	r.t.Helper()
+
+	require.JSONEq(r.t, expected, r.recorder.Body.String())
+	return r
+}
+
+// BodyEq checks the raw response body.
+func (r *Response) BodyEq(expected string) *Response {
+	r.t.Helper()
+
+	require.Equal(r.t, expected, r.recorder.Body.String())
+	return r
+}
+
+// ErrorCode checks that the response body is a "capo.ServerError" with the
+// code provided.
+func (r *Response) ErrorCode(code string) *Response {
+	r.t.Helper()
+
+	serverErr := &capo.ServerError{}
+	r.JSON(serverErr)
+	require.Equal(r.t, code, serverErr.Code, "unexpected error code")
+	return r
+}
+
+// Raw returns the recorded response.
+func (r *Response) Raw() *http.Response {
+	return r.recorder.Result()
+}
+
+// Body returns the raw response body.
+func (r *Response) Body() []byte {
+	return r.recorder.Body.Bytes()
+}
+
+// RequireErrorCode checks that the error provided is a "capo.ServerError" with
+// the code provided. It fails the test otherwise.
+func RequireErrorCode(t testing.TB, err error, code string) {
+	t.Helper()
+
+	serverErr := &capo.ServerError{}
+	require.ErrorAs(t, err, &serverErr, "the error is not a server error")
+	require.Equal(t, code, serverErr.Code, "unexpected error code")
+}
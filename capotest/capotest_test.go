@@ -0,0 +1,94 @@
+package capotest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+type testItem struct {
+	Name string `json:"name" xml:"name"`
+}
+
+// newTestServer creates a server with an items resource.
+func newTestServer(marshalers ...marshaler.Marshaler) *capo.Server {
+	s := capo.New()
+	s.SetMarshalers(marshalers...)
+	s.UseAfterAlways(capo.ErrorHandling)
+
+	s.Post("/items", func(ctx *capo.Context) error {
+		item := &testItem{}
+		if err := ctx.Read(item); err != nil {
+			return err
+		}
+
+		ctx.AddHeader("Location", "/items/"+item.Name)
+		ctx.SetStatus(http.StatusCreated)
+		ctx.Write(item)
+		return nil
+	})
+	s.Get("/items/{name}", func(ctx *capo.Context) error {
+		if ctx.Param("name") != "found" {
+			return capo.NewServerError(capo.NotFoundErrorCode, errors.New("missing item"))
+		}
+
+		ctx.Write(&testItem{Name: ctx.Param("name") + " " + ctx.Query("suffix") + " " + ctx.Request().Header.Get("X-Tenant")})
+		return nil
+	})
+
+	return s
+}
+
+func TestTesterJSONRequest(t *testing.T) {
+	item := &testItem{}
+	New(t, newTestServer()).
+		POST("/items").
+		JSON(&testItem{Name: "test"}).
+		Expect().
+		Status(http.StatusCreated).
+		Header("Location", "/items/test").
+		JSON(item).
+		JSONEq(`{"name":"test"}`)
+
+	require.Equal(t, "test", item.Name)
+}
+
+func TestTesterMarshalRequest(t *testing.T) {
+	s := newTestServer(&marshaler.JSONMarshaler{}, &marshaler.XMLMarshaler{})
+
+	New(t, s).
+		POST("/items").
+		Marshal(&marshaler.XMLMarshaler{}, &testItem{Name: "test"}).
+		Header("Accept", "application/json").
+		Expect().
+		Status(http.StatusCreated).
+		Header("Content-Type", "application/json").
+		JSONEq(`{"name":"test"}`)
+}
+
+func TestTesterQueryAndHeaders(t *testing.T) {
+	New(t, newTestServer()).
+		WithHeader("X-Tenant", "tenant").
+		GET("/items/found").
+		Query("suffix", "item").
+		Expect().
+		Status(http.StatusOK).
+		JSONEq(`{"name":"found item tenant"}`)
+}
+
+func TestTesterErrorCode(t *testing.T) {
+	New(t, newTestServer()).
+		GET("/items/missing").
+		Expect().
+		Status(http.StatusNotFound).
+		ErrorCode(capo.NotFoundErrorCode)
+}
+
+func TestRequireErrorCode(t *testing.T) {
+	err := capo.NewServerError(capo.ConflictErrorCode, nil)
+	RequireErrorCode(t, err, capo.ConflictErrorCode)
+}
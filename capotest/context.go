@@ -0,0 +1,100 @@
+package capotest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/mux"
+	"github.com/tonygcs/capo"
+)
+
+// Context is a request context to test the handlers without a server. The
+// response is written in a recorder.
+//
+//	ctx := capotest.NewContext(req, capotest.WithVars(map[string]string{"id": "42"}))
+//	ctx.Run(getItem, capotest.AfterAlways(capo.ErrorHandling))
+//
+//	require.Equal(t, http.StatusOK, ctx.Status())
+//	require.Equal(t, &Item{ID: 42}, ctx.ResponseData())
+type Context struct {
+	*capo.Context
+	recorder *httptest.ResponseRecorder
+}
+
+// ContextOption is an option to configure the test context.
+type ContextOption func(*http.Request) *http.Request
+
+// WithVars sets the path parameters of the request. e.g. the "id" parameter in
+// "/users/{id}".
+func WithVars(vars map[string]string) ContextOption {
+	return func(r *http.Request) *http.Request {
+		return mux.SetURLVars(r, vars)
+	}
+}
+
+// NewContext creates a test context for the request provided. If the request is
+// nil, it uses a GET request to "/".
+func NewContext(req *http.Request, opts ...ContextOption) *Context {
+	if req == nil {
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+	}
+
+	for _, opt := range opts {
+		req = opt(req)
+	}
+
+	recorder := httptest.NewRecorder()
+	return &Context{
+		Context:  capo.NewContext(recorder, req),
+		recorder: recorder,
+	}
+}
+
+// Recorder returns the recorder with the written response.
+func (ctx *Context) Recorder() *httptest.ResponseRecorder {
+	return ctx.recorder
+}
+
+// Response returns the written response.
+func (ctx *Context) Response() *http.Response {
+	return ctx.recorder.Result()
+}
+
+// Middleware adds a middleware to the chain that runs the handler.
+type Middleware func(*capo.Chain)
+
+// Before returns the middleware that runs the handlers provided before the
+// handler.
+func Before(handlers ...capo.Handler) Middleware {
+	return func(c *capo.Chain) { c.Before = append(c.Before, handlers...) }
+}
+
+// After returns the middleware that runs the handlers provided after the
+// handler if the request is not cancelled.
+func After(handlers ...capo.Handler) Middleware {
+	return func(c *capo.Chain) { c.After = append(c.After, handlers...) }
+}
+
+// AfterAlways returns the middleware that always runs the handlers provided
+// after the handler.
+func AfterAlways(handlers ...func(*capo.Context)) Middleware {
+	return func(c *capo.Chain) { c.AfterAlways = append(c.AfterAlways, handlers...) }
+}
+
+// Run runs the handler and the middlewares provided with the context, like a
+// server route does, and writes the response.
+func (ctx *Context) Run(handler capo.Handler, middlewares ...Middleware) *Context {
+	chain := &capo.Chain{Handler: handler}
+	for _, m := range middlewares {
+		m(chain)
+	}
+
+	chain.Serve(ctx.Context)
+	return ctx
+}
+
+// Run runs the handler and the middlewares provided with a GET request to "/"
+// and returns the context to inspect it.
+func Run(handler capo.Handler, middlewares ...Middleware) *Context {
+	return NewContext(nil).Run(handler, middlewares...)
+}
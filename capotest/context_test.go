@@ -0,0 +1,109 @@
+package capotest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo"
+)
+
+func TestNewContextWithVars(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/items/42", strings.NewReader(`{"name":"test"}`))
+	ctx := NewContext(req, WithVars(map[string]string{"id": "42"}))
+
+	id, err := ctx.ParamInt("id")
+	require.NoError(t, err)
+	require.Equal(t, 42, id)
+
+	item := &testItem{}
+	require.NoError(t, ctx.Read(item))
+	require.Equal(t, "test", item.Name)
+}
+
+func TestContextInspectors(t *testing.T) {
+	ctx := Run(func(ctx *capo.Context) error {
+		ctx.AddHeader("X-Item", "test")
+		ctx.SetStatus(http.StatusCreated)
+		ctx.Write(&testItem{Name: "test"})
+		return nil
+	})
+
+	require.NoError(t, ctx.Err())
+	require.Equal(t, http.StatusCreated, ctx.Status())
+	require.Equal(t, map[string]string{"X-Item": "test"}, ctx.ResponseHeaders())
+	require.Equal(t, &testItem{Name: "test"}, ctx.ResponseData())
+
+	res := ctx.Response()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	require.Equal(t, "test", res.Header.Get("X-Item"))
+	require.JSONEq(t, `{"name":"test"}`, ctx.Recorder().Body.String())
+}
+
+func TestRunMiddlewares(t *testing.T) {
+	errForbidden := capo.NewServerError(capo.ForbiddenErrorCode, errors.New("forbidden"))
+
+	tests := []struct {
+		name   string
+		before capo.Handler
+		calls  []string
+		status int
+	}{
+		{
+			name:   "allowed",
+			before: func(ctx *capo.Context) error { return nil },
+			calls:  []string{"handler", "after", "always"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "cancelled",
+			before: func(ctx *capo.Context) error { return errForbidden },
+			calls:  []string{"always"},
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := []string{}
+			ctx := Run(
+				func(ctx *capo.Context) error {
+					calls = append(calls, "handler")
+					return nil
+				},
+				Before(test.before),
+				After(func(ctx *capo.Context) error {
+					calls = append(calls, "after")
+					return nil
+				}),
+				AfterAlways(func(ctx *capo.Context) {
+					calls = append(calls, "always")
+				}, capo.ErrorHandling),
+			)
+
+			require.Equal(t, test.calls, calls)
+			require.Equal(t, test.status, ctx.Response().StatusCode)
+		})
+	}
+}
+
+func TestRunHandlerError(t *testing.T) {
+	ctx := Run(func(ctx *capo.Context) error {
+		return capo.NewServerError(capo.ConflictErrorCode, nil)
+	}, AfterAlways(capo.ErrorHandling))
+
+	RequireErrorCode(t, ctx.Err(), capo.ConflictErrorCode)
+	require.Equal(t, http.StatusConflict, ctx.Response().StatusCode)
+}
+
+func TestRunHandlerPanic(t *testing.T) {
+	ctx := Run(func(ctx *capo.Context) error {
+		panic("unexpected")
+	}, AfterAlways(capo.ErrorHandling))
+
+	require.EqualError(t, ctx.Err(), "unexpected")
+	require.Equal(t, http.StatusInternalServerError, ctx.Response().StatusCode)
+}
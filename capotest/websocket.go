@@ -0,0 +1,112 @@
+package capotest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/internal/mediatype"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// WSClient is a WebSocket client to test the WebSocket routes. The messages are
+// marshaled with the JSON marshaler unless another one is set.
+type WSClient struct {
+	t    testing.TB
+	conn *websocket.Conn
+	m    marshaler.Marshaler
+}
+
+// WebSocket connects to the WebSocket route in the path provided. It fails the
+// test if the connection cannot be established. The connection is closed when
+// the test finishes.
+func (tt *Tester) WebSocket(path string) *WSClient {
+	tt.t.Helper()
+
+	c, res, err := tt.DialWebSocket(path)
+	if err != nil && res != nil {
+		require.Failf(tt.t, "cannot connect to the websocket", "status: %d, error: %v", res.StatusCode, err)
+	}
+	require.NoError(tt.t, err, "cannot connect to the websocket")
+
+	return c
+}
+
+// DialWebSocket connects to the WebSocket route in the path provided. It
+// returns the handshake response, so the rejected connections can be checked.
+func (tt *Tester) DialWebSocket(path string) (*WSClient, *http.Response, error) {
+	s := httptest.NewServer(tt.handler)
+	tt.t.Cleanup(s.Close)
+
+	url := "ws" + strings.TrimPrefix(s.URL, "http") + path
+	conn, res, err := websocket.DefaultDialer.Dial(url, tt.headers)
+	if err != nil {
+		return nil, res, err
+	}
+	tt.t.Cleanup(func() { conn.Close() })
+
+	return &WSClient{t: tt.t, conn: conn, m: &marshaler.JSONMarshaler{}}, res, nil
+}
+
+// Marshaler sets the marshaler of the messages.
+func (c *WSClient) Marshaler(m marshaler.Marshaler) *WSClient {
+	c.m = m
+	return c
+}
+
+// Conn returns the underlying WebSocket connection.
+func (c *WSClient) Conn() *websocket.Conn {
+	return c.conn
+}
+
+// Send marshals the entity provided and sends it.
+func (c *WSClient) Send(entity interface{}) *WSClient {
+	c.t.Helper()
+
+	data, err := c.m.Marshal(entity)
+	require.NoError(c.t, err, "cannot marshal the message")
+
+	messageType := websocket.BinaryMessage
+	if mediatype.IsText(c.m.ContentTypeHeader()) {
+		messageType = websocket.TextMessage
+	}
+
+	require.NoError(c.t, c.conn.WriteMessage(messageType, data), "cannot send the message")
+	return c
+}
+
+// Receive reads the next message in the entity provided.
+func (c *WSClient) Receive(entity interface{}) *WSClient {
+	c.t.Helper()
+
+	_, data, err := c.conn.ReadMessage()
+	require.NoError(c.t, err, "cannot receive the message")
+	require.NoError(c.t, c.m.Unmarshal(data, entity), "invalid message: %s", data)
+	return c
+}
+
+// ExpectClose checks that the server closes the connection with the code
+// provided.
+func (c *WSClient) ExpectClose(code int) {
+	c.t.Helper()
+
+	_, _, err := c.conn.ReadMessage()
+
+	var closeErr *websocket.CloseError
+	require.True(c.t, errors.As(err, &closeErr), "the connection is not closed: %v", err)
+	require.Equal(c.t, code, closeErr.Code, "unexpected close code")
+}
+
+// Close sends the close message with the code provided and closes the
+// connection.
+func (c *WSClient) Close(code int) {
+	c.t.Helper()
+
+	err := c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, ""))
+	require.NoError(c.t, err, "cannot close the connection")
+	c.conn.Close()
+}
@@ -0,0 +1,66 @@
+package capotest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo"
+)
+
+func newWebSocketServer() *capo.Server {
+	s := capo.New()
+	s.UseAfterAlways(capo.ErrorHandling)
+
+	g := s.Group("/ws")
+	g.UseBefore(func(ctx *capo.Context) error {
+		if ctx.Request().Header.Get("X-Tenant") == "" {
+			return capo.NewServerError(capo.UnauthorizedErrorCode, errors.New("missing tenant"))
+		}
+		return nil
+	})
+	g.WebSocket("/echo", func(ctx *capo.Context, conn *capo.WSConn) error {
+		for {
+			item := &testItem{}
+			if err := conn.ReadMessage(item); err != nil {
+				return err
+			}
+
+			if item.Name == "forbidden" {
+				return capo.NewWSCloseError(capo.WSClosePolicyViolation, "forbidden item")
+			}
+
+			item.Name = ctx.Request().Header.Get("X-Tenant") + " " + item.Name
+			if err := conn.WriteMessage(item); err != nil {
+				return err
+			}
+		}
+	})
+
+	return s
+}
+
+func TestWebSocket(t *testing.T) {
+	tt := New(t, newWebSocketServer()).WithHeader("X-Tenant", "acme")
+
+	c := tt.WebSocket("/ws/echo")
+
+	item := &testItem{}
+	c.Send(&testItem{Name: "first"}).Receive(item)
+	require.Equal(t, "acme first", item.Name)
+
+	c.Send(&testItem{Name: "second"}).Receive(item)
+	require.Equal(t, "acme second", item.Name)
+
+	c.Send(&testItem{Name: "forbidden"}).ExpectClose(capo.WSClosePolicyViolation)
+}
+
+func TestDialWebSocketRejected(t *testing.T) {
+	tt := New(t, newWebSocketServer())
+
+	c, res, err := tt.DialWebSocket("/ws/echo")
+	require.Error(t, err)
+	require.Nil(t, c)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
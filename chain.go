@@ -0,0 +1,108 @@
+package capo
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Chain is a request handler with the middlewares that run around it. The
+// server routes run their handlers in a chain, and it can be used to run a
+// handler without a server, e.g. in the unit tests.
+type Chain struct {
+	// Before are the middlewares that run before the handler. The chain is
+	// cancelled if any of them returns an error.
+	Before []Handler
+	// Handler is the request handler. The chain is cancelled if it returns an
+	// error, so the error is available in "Context.Err" for the after always
+	// middlewares, e.g. "ErrorHandling", and the request context is done.
+	Handler Handler
+	// After are the middlewares that run after the handler if the chain is not
+	// cancelled.
+	After []Handler
+	// AfterAlways are the middlewares that always run at the end of the chain,
+	// even if it is cancelled or a handler panics.
+	AfterAlways []func(*Context)
+}
+
+// Serve runs the chain with the context provided and writes the response.
+func (c *Chain) Serve(ctx *Context) {
+	defer func() {
+		// Handle panics.
+		if rec := recover(); rec != nil {
+			var err error
+			switch r := rec.(type) {
+			case error:
+				err = r
+			default:
+				err = fmt.Errorf("%s", r)
+			}
+
+			ctx.Cancel(err)
+		}
+
+		// Run the middlewares that always will run after the request.
+		for _, h := range c.AfterAlways {
+			h(ctx)
+		}
+
+		// Close the response. It writes all data on it.
+		err := ctx.closeResponse()
+
+		// Handle any error on closing the response.
+		if err != nil {
+			// Log the error.
+			ctx.Logger().With("error", err.Error()).Error("invalid response content")
+
+			// Set the 500 status code.
+			m := ctx.marshalers().Default()
+			ctx.w.Header().Set("Content-Type", m.ContentTypeHeader())
+			ctx.w.WriteHeader(http.StatusInternalServerError)
+
+			// Set the response body with the internal error.
+			internalErr := NewServerError(InternalServerErrorCode, err).
+				WithStatus(http.StatusInternalServerError).
+				WithMessage(http.StatusText(http.StatusInternalServerError))
+			data, err := m.Marshal(internalErr)
+			if err != nil {
+				ctx.Logger().With("error", err.Error()).Error("the internal server error is invalid")
+			} else {
+				ctx.w.Write(data)
+			}
+		}
+
+		// Run the functions that wait for the response.
+		for _, fn := range ctx.onClose {
+			fn(ctx)
+		}
+	}()
+
+	var reqErr error
+
+	// Run before middlewares.
+	for _, h := range c.Before {
+		reqErr = h(ctx)
+		if reqErr != nil {
+			ctx.Cancel(reqErr)
+			break
+		}
+	}
+
+	// Run the request handler.
+	if reqErr == nil && c.Handler != nil {
+		reqErr = c.Handler(ctx)
+		if reqErr != nil {
+			ctx.Cancel(reqErr)
+		}
+	}
+
+	// Run after middlewares.
+	if reqErr == nil {
+		for _, h := range c.After {
+			reqErr = h(ctx)
+			if reqErr != nil {
+				ctx.Cancel(reqErr)
+				break
+			}
+		}
+	}
+}
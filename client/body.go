@@ -0,0 +1,157 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// FormContentType is the content type of the form request bodies.
+const FormContentType = "application/x-www-form-urlencoded"
+
+// FilePart is a file included in a multipart request body.
+type FilePart struct {
+	// FieldName is the form field name of the file.
+	FieldName string
+	// FileName is the name of the file.
+	FileName string
+	// ContentType is the file content type. It is
+	// "application/octet-stream" if it is not set.
+	ContentType string
+	// Reader is the file content.
+	Reader io.Reader
+}
+
+// multipartBody is the content of a multipart request body.
+type multipartBody struct {
+	fields url.Values
+	files  []FilePart
+}
+
+// payload is an encoded request body. The streamed bodies use the reader
+// instead of the data.
+type payload struct {
+	data        []byte
+	reader      io.Reader
+	length      int64
+	contentType string
+}
+
+// Form sets the values provided as the request body encoded as
+// "application/x-www-form-urlencoded". It replaces the request data.
+func (r *Request) Form(values url.Values) *Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resetBody()
+	r.form = values
+	return r
+}
+
+// Multipart sets a "multipart/form-data" request body with the fields and the
+// files provided. It replaces the request data. The files are read when the
+// request is performed.
+func (r *Request) Multipart(fields url.Values, files ...FilePart) *Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resetBody()
+	r.multipart = &multipartBody{fields: fields, files: files}
+	return r
+}
+
+// Body sets the reader provided as the request body. The body is streamed, so
+// the request is never retried. The length is the body size in bytes or -1 if
+// it is unknown. The content type is "application/octet-stream" unless the
+// "Content-Type" header is set.
+func (r *Request) Body(reader io.Reader, length int64) *Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resetBody()
+	r.stream = &payload{reader: reader, length: length, contentType: "application/octet-stream"}
+	return r
+}
+
+// resetBody removes the request body.
+func (r *Request) resetBody() {
+	r.data = nil
+	r.form = nil
+	r.multipart = nil
+	r.stream = nil
+}
+
+// encodeBody returns the request body encoded. It returns nil if the request
+// has no body.
+func (r *Request) encodeBody(m marshaler.Marshaler) (*payload, error) {
+	switch {
+	case r.stream != nil:
+		return r.stream, nil
+
+	case r.form != nil:
+		return &payload{data: []byte(r.form.Encode()), contentType: FormContentType}, nil
+
+	case r.multipart != nil:
+		return r.multipart.encode()
+
+	case r.data != nil:
+		data, err := m.Marshal(r.data)
+		if err != nil {
+			return nil, err
+		}
+
+		return &payload{data: data, contentType: m.ContentTypeHeader()}, nil
+	}
+
+	return nil, nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// encode writes the multipart body. The files are read, so the body can be
+// sent again if the request is retried.
+func (b *multipartBody) encode() (*payload, error) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+
+	for key, values := range b.fields {
+		for _, value := range values {
+			if err := w.WriteField(key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, file := range b.files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(file.FieldName), quoteEscaper.Replace(file.FileName)))
+		header.Set("Content-Type", contentType)
+
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return nil, fmt.Errorf("cannot read the file %s :: %w", file.FileName, err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return &payload{data: buf.Bytes(), contentType: w.FormDataContentType()}, nil
+}
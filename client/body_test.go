@@ -0,0 +1,62 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestForm(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, FormContentType, r.Header.Get("Content-Type"))
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "test", r.PostForm.Get("name"))
+		require.Equal(t, []string{"a", "b"}, r.PostForm["tag"])
+	}))
+	defer s.Close()
+
+	err := New(s.URL).Post("/").Form(url.Values{"name": {"test"}, "tag": {"a", "b"}}).Do(nil)
+	require.NoError(t, err)
+}
+
+func TestRequestMultipart(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1024))
+		require.Equal(t, "test", r.MultipartForm.Value["name"][0])
+
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer file.Close()
+
+		require.Equal(t, "file.txt", header.Filename)
+		require.Equal(t, "text/plain", header.Header.Get("Content-Type"))
+
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		require.Equal(t, "file content", string(content))
+	}))
+	defer s.Close()
+
+	err := New(s.URL).Post("/").Multipart(url.Values{"name": {"test"}}, FilePart{
+		FieldName:   "file",
+		FileName:    "file.txt",
+		ContentType: "text/plain",
+		Reader:      strings.NewReader("file content"),
+	}).Do(nil)
+	require.NoError(t, err)
+}
+
+func TestRequestBodyIsReplaced(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	}))
+	defer s.Close()
+
+	err := New(s.URL).Post("/").Form(url.Values{"name": {"test"}}).Data(&testData{}).Do(nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,302 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when the request is rejected because the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed is the state where all the requests are performed.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen is the state where all the requests are rejected.
+	CircuitOpen
+	// CircuitHalfOpen is the state where a limited number of requests are
+	// performed to check if the downstream service is back.
+	CircuitHalfOpen
+)
+
+// String returns the state name.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig is the circuit breaker configuration.
+type BreakerConfig struct {
+	// FailureRatio is the ratio of failed requests, between 0 and 1, that opens
+	// the circuit. The default value is 0.5.
+	FailureRatio float64
+	// MinRequests is the minimum number of requests in the window before the
+	// failure ratio is checked. The default value is 10.
+	MinRequests int
+	// Window is the time window where the requests are counted. The default
+	// value is 10 seconds.
+	Window time.Duration
+	// CoolDown is the time the circuit stays open before the half-open state.
+	// The default value is 5 seconds.
+	CoolDown time.Duration
+	// HalfOpenRequests is the number of requests allowed in the half-open
+	// state. The default value is 1.
+	HalfOpenRequests int
+	// PerHost creates a circuit breaker for every downstream host instead of
+	// one for the whole client.
+	PerHost bool
+	// IsFailure checks if the request result is a failure. By default, the
+	// connection errors and the 5xx status codes are failures.
+	IsFailure func(res *http.Response, err error) bool
+	// OnStateChange is called every time a circuit changes its state. The key
+	// is the host if "PerHost" is set. It must not block.
+	OnStateChange func(key string, from CircuitState, to CircuitState)
+}
+
+// DefaultBreakerConfig returns a circuit breaker configuration that opens the
+// circuit when half of the requests fail in a 10 seconds window.
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureRatio:     0.5,
+		MinRequests:      10,
+		Window:           10 * time.Second,
+		CoolDown:         5 * time.Second,
+		HalfOpenRequests: 1,
+	}
+}
+
+// WithCircuitBreaker sets a circuit breaker for the client requests.
+func WithCircuitBreaker(config BreakerConfig) Option {
+	return func(c *Client) { c.breakers = newBreakerSet(config) }
+}
+
+// isFailure is the default function to check if a request result is a
+// failure.
+func isFailure(res *http.Response, err error) bool {
+	return err != nil || res.StatusCode >= http.StatusInternalServerError
+}
+
+// breakerSet contains the circuit breakers of a client.
+type breakerSet struct {
+	mu       sync.Mutex
+	config   BreakerConfig
+	breakers map[string]*circuitBreaker
+}
+
+func newBreakerSet(config BreakerConfig) *breakerSet {
+	// Set the default values. Otherwise, any request result opens the circuit,
+	// the open circuit does not reject any request or the requests are counted
+	// forever.
+	defaults := DefaultBreakerConfig()
+	if config.FailureRatio <= 0 {
+		config.FailureRatio = defaults.FailureRatio
+	}
+	if config.MinRequests < 1 {
+		config.MinRequests = defaults.MinRequests
+	}
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = defaults.CoolDown
+	}
+	if config.HalfOpenRequests < 1 {
+		config.HalfOpenRequests = defaults.HalfOpenRequests
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isFailure
+	}
+
+	return &breakerSet{
+		config:   config,
+		breakers: map[string]*circuitBreaker{},
+	}
+}
+
+// get returns the circuit breaker for the request provided.
+func (s *breakerSet) get(req *http.Request) *circuitBreaker {
+	key := ""
+	if s.config.PerHost {
+		key = req.URL.Host
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.breakers[key]
+	if !ok {
+		b = &circuitBreaker{key: key, config: &s.config}
+		s.breakers[key] = b
+	}
+
+	return b
+}
+
+// do performs the request if the circuit breaker allows it and records the
+// result.
+func (s *breakerSet) do(req *http.Request, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if s == nil {
+		return do(req)
+	}
+
+	b := s.get(req)
+	t, ok := b.allow(time.Now())
+	if !ok {
+		return nil, ErrCircuitOpen
+	}
+
+	res, err := do(req)
+
+	// The requests cancelled by the caller are not a downstream failure.
+	if errors.Is(err, context.Canceled) {
+		b.release(t)
+		return res, err
+	}
+
+	b.record(time.Now(), t, s.config.IsFailure(res, err))
+	return res, err
+}
+
+// ticket identifies a request allowed by a circuit breaker. The probes are the
+// requests allowed in the half-open state.
+type ticket struct {
+	probe      bool
+	generation int
+}
+
+// circuitBreaker is the circuit breaker for a downstream service.
+type circuitBreaker struct {
+	mu     sync.Mutex
+	key    string
+	config *BreakerConfig
+
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	inFlight    int
+	generation  int
+}
+
+// allow checks if a request can be performed and returns its ticket.
+func (b *circuitBreaker) allow(now time.Time) (ticket, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen {
+		if now.Sub(b.openedAt) < b.config.CoolDown {
+			return ticket{}, false
+		}
+
+		b.setState(CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.inFlight >= b.config.HalfOpenRequests {
+			return ticket{}, false
+		}
+
+		b.inFlight++
+		return ticket{probe: true, generation: b.generation}, true
+	}
+
+	return ticket{}, true
+}
+
+// isProbe checks if the ticket is a probe of the current half-open state.
+func (b *circuitBreaker) isProbe(t ticket) bool {
+	return b.state == CircuitHalfOpen && t.probe && t.generation == b.generation
+}
+
+// release frees the half-open slot of a request without result.
+func (b *circuitBreaker) release(t ticket) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.isProbe(t) {
+		b.inFlight--
+	}
+}
+
+// record updates the circuit with the result of a request.
+func (b *circuitBreaker) record(now time.Time, t ticket, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitHalfOpen:
+		// Only the probes change the half-open state. The results of the
+		// requests allowed before are outdated.
+		if !b.isProbe(t) {
+			return
+		}
+
+		b.inFlight--
+		if failed {
+			b.open(now)
+		} else {
+			b.reset(now)
+			b.setState(CircuitClosed)
+		}
+
+	case CircuitClosed:
+		if b.config.Window > 0 && now.Sub(b.windowStart) >= b.config.Window {
+			b.reset(now)
+		}
+
+		b.requests++
+		if failed {
+			b.failures++
+		}
+
+		if b.requests >= b.config.MinRequests &&
+			float64(b.failures)/float64(b.requests) >= b.config.FailureRatio {
+			b.open(now)
+		}
+	}
+}
+
+// open opens the circuit. The probes of the previous half-open state are
+// discarded.
+func (b *circuitBreaker) open(now time.Time) {
+	b.openedAt = now
+	b.inFlight = 0
+	b.generation++
+	b.setState(CircuitOpen)
+}
+
+// reset starts a new counting window.
+func (b *circuitBreaker) reset(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
+
+// setState changes the circuit state and notifies the change.
+func (b *circuitBreaker) setState(state CircuitState) {
+	if b.state == state {
+		return
+	}
+
+	from := b.state
+	b.state = state
+
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(b.key, from, state)
+	}
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBreakerConfig returns a circuit breaker configuration that opens the
+// circuit after two failed requests.
+func testBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  2,
+		Window:       time.Minute,
+		CoolDown:     50 * time.Millisecond,
+	}
+}
+
+// statusHandler responds with the status stored in the value provided.
+func statusHandler(status *int32, requests *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.WriteHeader(int(atomic.LoadInt32(status)))
+	}
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	status := int32(http.StatusServiceUnavailable)
+	var requests int32
+	s := httptest.NewServer(statusHandler(&status, &requests))
+	defer s.Close()
+	c := New(s.URL, WithCircuitBreaker(testBreakerConfig()))
+
+	require.Error(t, c.Get("/").Do(nil))
+	require.Error(t, c.Get("/").Do(nil))
+
+	err := c.Get("/").Do(nil)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	status := int32(http.StatusNotFound)
+	var requests int32
+	s := httptest.NewServer(statusHandler(&status, &requests))
+	defer s.Close()
+	c := New(s.URL, WithCircuitBreaker(testBreakerConfig()))
+
+	for i := 0; i < 5; i++ {
+		err := c.Get("/").Do(nil)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	require.Equal(t, int32(5), atomic.LoadInt32(&requests))
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	status := int32(http.StatusServiceUnavailable)
+	var requests int32
+	s := httptest.NewServer(statusHandler(&status, &requests))
+	defer s.Close()
+
+	var mu sync.Mutex
+	var changes []string
+	config := testBreakerConfig()
+	config.OnStateChange = func(key string, from CircuitState, to CircuitState) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, fmt.Sprintf("%s->%s", from, to))
+	}
+	c := New(s.URL, WithCircuitBreaker(config))
+
+	// Open the circuit.
+	c.Get("/").Do(nil)
+	c.Get("/").Do(nil)
+	require.ErrorIs(t, c.Get("/").Do(nil), ErrCircuitOpen)
+
+	// The probe request fails and the circuit is opened again.
+	time.Sleep(config.CoolDown)
+	err := c.Get("/").Do(nil)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrCircuitOpen)
+	require.ErrorIs(t, c.Get("/").Do(nil), ErrCircuitOpen)
+
+	// The probe request succeeds and the circuit is closed.
+	atomic.StoreInt32(&status, http.StatusOK)
+	time.Sleep(config.CoolDown)
+	require.NoError(t, c.Get("/").Do(nil))
+	require.NoError(t, c.Get("/").Do(nil))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, changes)
+}
+
+func TestCircuitBreakerPerHost(t *testing.T) {
+	failing := int32(http.StatusServiceUnavailable)
+	healthy := int32(http.StatusOK)
+	var requests int32
+	s1 := httptest.NewServer(statusHandler(&failing, &requests))
+	defer s1.Close()
+	s2 := httptest.NewServer(statusHandler(&healthy, &requests))
+	defer s2.Close()
+
+	config := testBreakerConfig()
+	config.PerHost = true
+	c := New("", WithCircuitBreaker(config))
+
+	c.Get("/").URL(s1.URL).Do(nil)
+	c.Get("/").URL(s1.URL).Do(nil)
+	require.ErrorIs(t, c.Get("/").URL(s1.URL).Do(nil), ErrCircuitOpen)
+	require.NoError(t, c.Get("/").URL(s2.URL).Do(nil))
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	status := int32(http.StatusServiceUnavailable)
+	var requests int32
+	s := httptest.NewServer(statusHandler(&status, &requests))
+	defer s.Close()
+
+	config := testBreakerConfig()
+	config.Window = 20 * time.Millisecond
+	c := New(s.URL, WithCircuitBreaker(config))
+
+	// The failures in different windows do not open the circuit.
+	require.Error(t, c.Get("/").Do(nil))
+	time.Sleep(config.Window)
+	require.Error(t, c.Get("/").Do(nil))
+	err := c.Get("/").Do(nil)
+	require.NotErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreakerIsNotRetried(t *testing.T) {
+	status := int32(http.StatusServiceUnavailable)
+	var requests int32
+	s := httptest.NewServer(statusHandler(&status, &requests))
+	defer s.Close()
+	c := New(s.URL, WithCircuitBreaker(testBreakerConfig()), WithRetryPolicy(testRetryPolicy()))
+
+	require.ErrorIs(t, c.Get("/").Do(nil), ErrCircuitOpen)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestCircuitBreakerDefaultConfig(t *testing.T) {
+	status := int32(http.StatusOK)
+	var requests int32
+	s := httptest.NewServer(statusHandler(&status, &requests))
+	defer s.Close()
+	c := New(s.URL, WithCircuitBreaker(BreakerConfig{Window: time.Minute, CoolDown: time.Minute}))
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, c.Get("/").Do(nil))
+	}
+	require.Equal(t, int32(20), atomic.LoadInt32(&requests))
+}
+
+func TestCircuitBreakerPartialConfig(t *testing.T) {
+	set := newBreakerSet(BreakerConfig{PerHost: true})
+	defaults := DefaultBreakerConfig()
+	require.Equal(t, defaults.Window, set.config.Window)
+	require.Equal(t, defaults.CoolDown, set.config.CoolDown)
+	require.Equal(t, defaults.FailureRatio, set.config.FailureRatio)
+	require.Equal(t, defaults.MinRequests, set.config.MinRequests)
+	require.Equal(t, defaults.HalfOpenRequests, set.config.HalfOpenRequests)
+
+	b := set.get(httptest.NewRequest(http.MethodGet, "/", nil))
+	now := time.Now()
+	for i := 0; i < defaults.MinRequests; i++ {
+		tk, ok := b.allow(now)
+		require.True(t, ok)
+		b.record(now, tk, true)
+	}
+	require.Equal(t, CircuitOpen, b.state)
+
+	// The open circuit rejects the requests until the cool down ends.
+	_, ok := b.allow(now.Add(time.Second))
+	require.False(t, ok)
+	require.Equal(t, CircuitOpen, b.state)
+}
+
+func TestCircuitBreakerIgnoresOutdatedResults(t *testing.T) {
+	config := testBreakerConfig()
+	b := newBreakerSet(config).get(httptest.NewRequest(http.MethodGet, "/", nil))
+	now := time.Now()
+
+	// The request is allowed in the closed state and finishes later.
+	outdated, ok := b.allow(now)
+	require.True(t, ok)
+
+	for i := 0; i < config.MinRequests; i++ {
+		tk, ok := b.allow(now)
+		require.True(t, ok)
+		b.record(now, tk, true)
+	}
+	require.Equal(t, CircuitOpen, b.state)
+
+	now = now.Add(config.CoolDown)
+	probe, ok := b.allow(now)
+	require.True(t, ok)
+	require.Equal(t, CircuitHalfOpen, b.state)
+
+	// The outdated result does not free the probe slot nor close the circuit.
+	b.record(now, outdated, false)
+	require.Equal(t, CircuitHalfOpen, b.state)
+	_, ok = b.allow(now)
+	require.False(t, ok)
+
+	b.record(now, probe, false)
+	require.Equal(t, CircuitClosed, b.state)
+}
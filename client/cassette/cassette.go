@@ -0,0 +1,406 @@
+// Package cassette provides a http client that records the http interactions
+// in a file and replays them, so the tests of the code built on the "client"
+// package can run offline and deterministically.
+//
+//	c, err := cassette.New("testdata/partner.json", cassette.WithMode(cassette.ModeAuto))
+//	...
+//	defer c.Save()
+//
+//	partner := client.New("https://partner.example.com", client.WithHTTPClient(c))
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrInteractionNotFound is returned in replay mode when there is no recorded
+// interaction for the request.
+var ErrInteractionNotFound = errors.New("cassette interaction not found")
+
+// RedactedValue replaces the value of the redacted headers and query
+// parameters.
+const RedactedValue = "REDACTED"
+
+// Mode is the cassette mode.
+type Mode int
+
+const (
+	// ModeReplay returns the recorded responses and never performs a real
+	// request.
+	ModeReplay Mode = iota
+	// ModeRecord performs the real requests and records them. The existing
+	// interactions are discarded.
+	ModeRecord
+	// ModeAuto returns the recorded responses and records the requests that
+	// were not recorded.
+	ModeAuto
+)
+
+// Request is a recorded http request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Response is a recorded http response.
+type Response struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Interaction is a recorded http request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Body is a recorded http body. It is stored as a string if it is valid UTF-8
+// text, otherwise it is stored encoded in base64.
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = Body(text)
+		return nil
+	}
+
+	encoded := map[string]string{}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded["base64"])
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+	return nil
+}
+
+// httpClient performs the real http requests.
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Option is an option to configure the cassette.
+type Option func(*Cassette)
+
+// WithMode sets the cassette mode. The default mode is "ModeReplay".
+func WithMode(mode Mode) Option {
+	return func(c *Cassette) { c.mode = mode }
+}
+
+// WithMatcher sets the function that finds the recorded interaction of a
+// request. The default matcher compares the method, path, query and body.
+func WithMatcher(matcher Matcher) Option {
+	return func(c *Cassette) { c.matcher = matcher }
+}
+
+// WithRedactedHeaders adds headers that are not stored in the file with their
+// real value.
+func WithRedactedHeaders(headers ...string) Option {
+	return func(c *Cassette) { c.redacted = append(c.redacted, headers...) }
+}
+
+// WithRedactedQuery adds query parameters that are not stored in the file with
+// their real value. The parameter names are case insensitive.
+func WithRedactedQuery(params ...string) Option {
+	return func(c *Cassette) { c.redactedQuery = append(c.redactedQuery, params...) }
+}
+
+// WithRedactor adds a function that removes the sensitive data of the
+// interactions before they are stored, e.g. the tokens in the bodies. It runs
+// after the headers and query parameters are redacted.
+func WithRedactor(fn func(interaction *Interaction)) Option {
+	return func(c *Cassette) { c.redactors = append(c.redactors, fn) }
+}
+
+// WithHTTPClient sets the http client that performs the real requests.
+func WithHTTPClient(hc httpClient) Option {
+	return func(c *Cassette) { c.httpClient = hc }
+}
+
+// defaultRedactedHeaders are the headers always redacted.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// defaultRedactedQuery are the query parameters always redacted.
+var defaultRedactedQuery = []string{
+	"access_token",
+	"api_key",
+	"apikey",
+	"client_secret",
+	"key",
+	"password",
+	"token",
+}
+
+// Cassette is a http client that records and replays http interactions.
+type Cassette struct {
+	mu sync.Mutex
+
+	path          string
+	mode          Mode
+	matcher       Matcher
+	redacted      []string
+	redactedQuery []string
+	redactors     []func(*Interaction)
+	httpClient    httpClient
+
+	interactions []*Interaction
+	used         map[*Interaction]bool
+}
+
+// New creates a cassette stored in the file provided. The recorded
+// interactions are loaded unless the mode is "ModeRecord".
+func New(path string, opts ...Option) (*Cassette, error) {
+	c := &Cassette{
+		path:          path,
+		mode:          ModeReplay,
+		matcher:       DefaultMatcher,
+		redacted:      append([]string(nil), defaultRedactedHeaders...),
+		redactedQuery: append([]string(nil), defaultRedactedQuery...),
+		httpClient:    http.DefaultClient,
+		used:          map[*Interaction]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.mode == ModeRecord {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && c.mode == ModeAuto {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the cassette file :: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("invalid cassette file format :: %w", err)
+	}
+
+	return c, nil
+}
+
+// Interactions returns the interactions in the cassette.
+func (c *Cassette) Interactions() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]*Interaction(nil), c.interactions...)
+}
+
+// Save writes the interactions in the cassette file. It does nothing in
+// "ModeReplay".
+func (c *Cassette) Save() error {
+	if c.mode == ModeReplay {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("cannot marshal the cassette interactions :: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, data, 0o644); err != nil {
+		return fmt.Errorf("cannot write the cassette file :: %w", err)
+	}
+
+	return nil
+}
+
+// Do returns the recorded response of the request or performs it and records
+// the interaction depending on the cassette mode.
+func (c *Cassette) Do(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the request body :: %w", err)
+	}
+
+	if c.mode != ModeRecord {
+		if interaction := c.find(req, body); interaction != nil {
+			return interaction.Response.httpResponse(req), nil
+		}
+
+		if c.mode == ModeReplay {
+			return nil, fmt.Errorf("%w :: %s %s", ErrInteractionNotFound, req.Method, req.URL)
+		}
+	}
+
+	return c.record(req, body)
+}
+
+// find returns the recorded interaction of the request. The interactions not
+// used yet take precedence, so the same request can have different responses.
+func (c *Cassette) find(req *http.Request, body []byte) *Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var found *Interaction
+	for _, interaction := range c.interactions {
+		if !c.matcher(req, body, &interaction.Request) {
+			continue
+		}
+
+		if !c.used[interaction] {
+			c.used[interaction] = true
+			return interaction
+		}
+
+		if found == nil {
+			found = interaction
+		}
+	}
+
+	return found
+}
+
+// record performs the real request and records the interaction.
+func (c *Cassette) record(req *http.Request, body []byte) (*http.Response, error) {
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the response body :: %w", err)
+	}
+
+	interaction := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    c.redactURL(req.URL),
+			Header: c.redact(req.Header),
+			Body:   body,
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Header:     c.redact(res.Header),
+			Body:       resBody,
+		},
+	}
+
+	for _, redact := range c.redactors {
+		redact(interaction)
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, interaction)
+	c.used[interaction] = true
+	c.mu.Unlock()
+
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+	return res, nil
+}
+
+// redact returns a copy of the headers with the sensitive values replaced.
+func (c *Cassette) redact(header http.Header) http.Header {
+	result := header.Clone()
+	for _, key := range c.redacted {
+		if _, ok := result[http.CanonicalHeaderKey(key)]; ok {
+			result.Set(key, RedactedValue)
+		}
+	}
+
+	return result
+}
+
+// redactURL returns the URL with the sensitive query parameters and user
+// information replaced.
+func (c *Cassette) redactURL(u *url.URL) string {
+	result := *u
+	if result.User != nil {
+		result.User = url.User(RedactedValue)
+	}
+
+	query := result.Query()
+	redacted := false
+	for key, values := range query {
+		for _, param := range c.redactedQuery {
+			if !strings.EqualFold(key, param) {
+				continue
+			}
+
+			for i := range values {
+				values[i] = RedactedValue
+			}
+			redacted = true
+		}
+	}
+
+	if redacted {
+		result.RawQuery = query.Encode()
+	}
+
+	return result.String()
+}
+
+// httpResponse returns the http response of the recorded response.
+func (r *Response) httpResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// readRequestBody reads the request body and sets a new reader, so the request
+// can be sent.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
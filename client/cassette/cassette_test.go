@@ -0,0 +1,216 @@
+package cassette_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/client"
+	"github.com/tonygcs/capo/client/cassette"
+	"github.com/tonygcs/capo/generic"
+)
+
+type testData struct {
+	Message string `json:"msg"`
+}
+
+// testHandler returns the request number in the response message.
+func testHandler() http.HandlerFunc {
+	var requests int32
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"msg":"%s %s %d"}`, r.Method, r.URL.Path, n)
+	}
+}
+
+// record records the requests performed by the function provided in the
+// cassette file and returns the server url.
+func record(t *testing.T, path string, do func(c *client.Client), opts ...cassette.Option) string {
+	s := httptest.NewServer(testHandler())
+	defer s.Close()
+
+	rec, err := cassette.New(path, append(opts, cassette.WithMode(cassette.ModeRecord))...)
+	require.NoError(t, err)
+
+	do(client.New(s.URL, client.WithHTTPClient(rec)))
+	require.NoError(t, rec.Save())
+
+	return s.URL
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	url := record(t, path, func(c *client.Client) {
+		require.NoError(t, c.Get("items").Query("page", "1").Do(nil))
+		require.NoError(t, c.Post("items").Data(&testData{Message: "new"}).Do(nil))
+	})
+
+	replay, err := cassette.New(path)
+	require.NoError(t, err)
+	c := client.New(url, client.WithHTTPClient(replay))
+
+	res := &testData{}
+	require.NoError(t, c.Get("items").Query("page", "1").Do(res))
+	require.Equal(t, "GET /items 1", res.Message)
+
+	item, err := generic.NewRequest[testData, testData](c).Method(http.MethodPost).RelativePath("items").Data(&testData{Message: "new"}).Do()
+	require.NoError(t, err)
+	require.Equal(t, "POST /items 2", item.Message)
+}
+
+func TestCassetteReplayNotFound(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	url := record(t, path, func(c *client.Client) {
+		require.NoError(t, c.Get("items").Query("page", "1").Do(nil))
+		require.NoError(t, c.Post("items").Data(&testData{Message: "new"}).Do(nil))
+	})
+
+	replay, err := cassette.New(path)
+	require.NoError(t, err)
+	c := client.New(url, client.WithHTTPClient(replay))
+
+	tests := map[string]*client.Request{
+		"method": c.Delete("items").Query("page", "1"),
+		"path":   c.Get("other").Query("page", "1"),
+		"query":  c.Get("items").Query("page", "2"),
+		"body":   c.Post("items").Data(&testData{Message: "other"}),
+	}
+
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := req.Do(nil)
+			require.True(t, errors.Is(err, cassette.ErrInteractionNotFound), err)
+		})
+	}
+}
+
+func TestCassetteCustomMatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	url := record(t, path, func(c *client.Client) {
+		require.NoError(t, c.Post("items").Data(&testData{Message: "new"}).Do(nil))
+	})
+
+	replay, err := cassette.New(path, cassette.WithMatcher(cassette.MatchAll(cassette.MatchMethod, cassette.MatchPath)))
+	require.NoError(t, err)
+	c := client.New(url, client.WithHTTPClient(replay))
+
+	res := &testData{}
+	require.NoError(t, c.Post("items").Data(&testData{Message: "other"}).Do(res))
+	require.Equal(t, "POST /items 1", res.Message)
+}
+
+func TestCassetteReplaysRequestsInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	url := record(t, path, func(c *client.Client) {
+		require.NoError(t, c.Get("items").Do(nil))
+		require.NoError(t, c.Get("items").Do(nil))
+	})
+
+	replay, err := cassette.New(path)
+	require.NoError(t, err)
+	c := client.New(url, client.WithHTTPClient(replay))
+
+	var messages []string
+	for i := 0; i < 3; i++ {
+		res := &testData{}
+		require.NoError(t, c.Get("items").Do(res))
+		messages = append(messages, res.Message)
+	}
+	require.Equal(t, []string{"GET /items 1", "GET /items 2", "GET /items 1"}, messages)
+}
+
+func TestCassetteRedactsHeaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	record(t, path, func(c *client.Client) {
+		err := c.Get("items").
+			AddHeader("Authorization", "Bearer secret").
+			AddHeader("X-Partner-Token", "secret").
+			Do(nil)
+		require.NoError(t, err)
+	}, cassette.WithRedactedHeaders("X-Partner-Token"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret")
+	require.True(t, strings.Contains(string(data), cassette.RedactedValue))
+}
+
+func TestCassetteRedactsQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	url := record(t, path, func(c *client.Client) {
+		err := c.Get("items").
+			Query("api_key", "secret").
+			Query("Partner_Token", "secret").
+			Query("page", "1").
+			Do(nil)
+		require.NoError(t, err)
+	}, cassette.WithRedactedQuery("partner_token"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret")
+	require.Contains(t, string(data), "page=1")
+
+	// The redacted parameters match any value.
+	replay, err := cassette.New(path)
+	require.NoError(t, err)
+	c := client.New(url, client.WithHTTPClient(replay))
+
+	res := &testData{}
+	err = c.Get("items").Query("api_key", "other").Query("Partner_Token", "other").Query("page", "1").Do(res)
+	require.NoError(t, err)
+	require.Equal(t, "GET /items 1", res.Message)
+
+	err = c.Get("items").Query("api_key", "other").Query("Partner_Token", "other").Query("page", "2").Do(nil)
+	require.ErrorIs(t, err, cassette.ErrInteractionNotFound)
+}
+
+func TestCassetteRedactor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	record(t, path, func(c *client.Client) {
+		res := &testData{}
+		require.NoError(t, c.Post("login").Data(&testData{Message: "secret"}).Do(res))
+
+		// The response is not changed by the redactor.
+		require.Equal(t, "POST /login 1", res.Message)
+	}, cassette.WithRedactor(func(interaction *cassette.Interaction) {
+		interaction.Request.Body = cassette.Body(strings.ReplaceAll(string(interaction.Request.Body), "secret", cassette.RedactedValue))
+		interaction.Response.Body = cassette.Body(`{"msg":"redacted"}`)
+	}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret")
+	require.NotContains(t, string(data), "POST /login 1")
+}
+
+func TestCassetteAutoMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	s := httptest.NewServer(testHandler())
+	defer s.Close()
+
+	auto, err := cassette.New(path, cassette.WithMode(cassette.ModeAuto))
+	require.NoError(t, err)
+	c := client.New(s.URL, client.WithHTTPClient(auto))
+
+	res := &testData{}
+	require.NoError(t, c.Get("items").Do(res))
+	require.Equal(t, "GET /items 1", res.Message)
+
+	// The recorded interaction is replayed.
+	require.NoError(t, c.Get("items").Do(res))
+	require.Equal(t, "GET /items 1", res.Message)
+
+	require.NoError(t, c.Get("other").Do(res))
+	require.Equal(t, "GET /other 2", res.Message)
+	require.Len(t, auto.Interactions(), 2)
+}
@@ -0,0 +1,91 @@
+package cassette
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+)
+
+// Matcher checks if a recorded request matches the request provided.
+type Matcher func(req *http.Request, body []byte, recorded *Request) bool
+
+// DefaultMatcher matches the requests with the same method, path, query and
+// body.
+var DefaultMatcher = MatchAll(MatchMethod, MatchPath, MatchQuery, MatchBody)
+
+// MatchAll returns a matcher that matches the requests if all the matchers
+// provided match them.
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(req *http.Request, body []byte, recorded *Request) bool {
+		for _, match := range matchers {
+			if !match(req, body, recorded) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// MatchMethod matches the requests with the same method.
+func MatchMethod(req *http.Request, body []byte, recorded *Request) bool {
+	return req.Method == recorded.Method
+}
+
+// MatchPath matches the requests with the same host and path.
+func MatchPath(req *http.Request, body []byte, recorded *Request) bool {
+	u, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+
+	return req.URL.Host == u.Host && req.URL.Path == u.Path
+}
+
+// MatchQuery matches the requests with the same query parameters in any
+// order. The redacted parameters match any value.
+func MatchQuery(req *http.Request, body []byte, recorded *Request) bool {
+	u, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+
+	query := req.URL.Query()
+	recordedQuery := u.Query()
+	if len(query) != len(recordedQuery) {
+		return false
+	}
+
+	for key, recordedValues := range recordedQuery {
+		values := query[key]
+		if len(values) != len(recordedValues) {
+			return false
+		}
+
+		for i, value := range values {
+			if !matchValue(value, recordedValues[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// MatchBody matches the requests with the same body.
+func MatchBody(req *http.Request, body []byte, recorded *Request) bool {
+	return bytes.Equal(body, recorded.Body)
+}
+
+// MatchHeader returns a matcher that matches the requests with the same value
+// in the header provided. The redacted headers match any value.
+func MatchHeader(key string) Matcher {
+	return func(req *http.Request, body []byte, recorded *Request) bool {
+		return matchValue(req.Header.Get(key), recorded.Header.Get(key))
+	}
+}
+
+// matchValue checks if the value matches the recorded one.
+func matchValue(value string, recorded string) bool {
+	return recorded == RedactedValue || value == recorded
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// Client performs the requests to a downstream service. It keeps the
+// configuration shared by all the requests, so it should be created once per
+// service.
+type Client struct {
+	baseURL      string
+	headers      map[string]string
+	timeout      time.Duration
+	marshaler    marshaler.Marshaler
+	httpClient   httpClient
+	retryPolicy  *RetryPolicy
+	breakers     *breakerSet
+	interceptors []Interceptor
+
+	maxResponseSize int64
+}
+
+// Option is an option to configure the client.
+type Option func(*Client)
+
+// WithHeader sets a header that will be included in every request. The request
+// headers take precedence over it.
+func WithHeader(key string, value string) Option {
+	return func(c *Client) { c.headers[key] = value }
+}
+
+// WithTimeout sets the maximum duration of every request, including the time to
+// read the response body.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) { c.timeout = timeout }
+}
+
+// WithMarshaler sets the marshaler for the request and response bodies.
+func WithMarshaler(m marshaler.Marshaler) Option {
+	return func(c *Client) { c.marshaler = m }
+}
+
+// WithHTTPClient sets the http client that will request the server.
+func WithHTTPClient(hc httpClient) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// New creates a new client for the service in the base url provided.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: baseURL,
+		headers: make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// NewRequest returns a new http request to the client base url.
+func (c *Client) NewRequest() *Request {
+	r := NewRequest().URL(c.baseURL)
+	r.client = c
+	return r
+}
+
+// Get returns a new GET request to the relative path provided.
+func (c *Client) Get(relativePath string) *Request {
+	return c.NewRequest().Method(http.MethodGet).RelativePath(relativePath)
+}
+
+// Post returns a new POST request to the relative path provided.
+func (c *Client) Post(relativePath string) *Request {
+	return c.NewRequest().Method(http.MethodPost).RelativePath(relativePath)
+}
+
+// Put returns a new PUT request to the relative path provided.
+func (c *Client) Put(relativePath string) *Request {
+	return c.NewRequest().Method(http.MethodPut).RelativePath(relativePath)
+}
+
+// Patch returns a new PATCH request to the relative path provided.
+func (c *Client) Patch(relativePath string) *Request {
+	return c.NewRequest().Method(http.MethodPatch).RelativePath(relativePath)
+}
+
+// Delete returns a new DELETE request to the relative path provided.
+func (c *Client) Delete(relativePath string) *Request {
+	return c.NewRequest().Method(http.MethodDelete).RelativePath(relativePath)
+}
+
+// getHTTPClient returns the http client for the requests. If it is not set, it
+// returns the package http client.
+func (c *Client) getHTTPClient() httpClient {
+	if c != nil && c.httpClient != nil {
+		return c.httpClient
+	}
+
+	return defaultHTTPClient
+}
+
+// getMarshaler returns the marshaler for the requests. If it is not set, it
+// returns the default marshaler of the "marshaler" package.
+func (c *Client) getMarshaler() marshaler.Marshaler {
+	if c != nil && c.marshaler != nil {
+		return c.marshaler
+	}
+
+	return marshaler.GetMarshaler()
+}
+
+// getTimeout returns the maximum duration of the requests.
+func (c *Client) getTimeout() time.Duration {
+	if c == nil {
+		return 0
+	}
+
+	return c.timeout
+}
+
+// getHeaders returns the headers for all the requests.
+func (c *Client) getHeaders() map[string]string {
+	if c == nil {
+		return nil
+	}
+
+	return c.headers
+}
+
+// getRetryPolicy returns the retry policy for the requests.
+func (c *Client) getRetryPolicy() *RetryPolicy {
+	if c == nil {
+		return nil
+	}
+
+	return c.retryPolicy
+}
+
+// getBreakers returns the circuit breakers for the requests.
+func (c *Client) getBreakers() *breakerSet {
+	if c == nil {
+		return nil
+	}
+
+	return c.breakers
+}
+
+// getMaxResponseSize returns the maximum size of the response bodies.
+func (c *Client) getMaxResponseSize() int64 {
+	if c == nil {
+		return 0
+	}
+
+	return c.maxResponseSize
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+type testData struct {
+	Message string `json:"msg"`
+}
+
+// testHandler returns the request method, path and headers in the response
+// message.
+func testHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		msg := fmt.Sprintf("%s %s %s %s", r.Method, r.URL.Path, r.Header.Get("X-Service"), r.Header.Get("X-Request"))
+		json.NewEncoder(w).Encode(&testData{Message: msg})
+	}
+}
+
+func TestClientsWithDifferentBaseURLs(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		service := fmt.Sprintf("service-%d", i)
+		t.Run(service, func(t *testing.T) {
+			t.Parallel()
+
+			s := httptest.NewServer(testHandler())
+			defer s.Close()
+			c := New(s.URL+"/api", WithHeader("X-Service", service))
+
+			res := &testData{}
+			err := c.Post("items").AddHeader("X-Request", "request").Do(res)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("POST /api/items %s request", service), res.Message)
+		})
+	}
+}
+
+func TestClientRequestHeadersTakePrecedence(t *testing.T) {
+	s := httptest.NewServer(testHandler())
+	defer s.Close()
+	c := New(s.URL, WithHeader("X-Service", "client"), WithHeader("X-Request", "client"))
+
+	res := &testData{}
+	err := c.Get("/").AddHeader("X-Request", "request").Do(res)
+	require.NoError(t, err)
+	require.Equal(t, "GET / client request", res.Message)
+}
+
+func TestClientTimeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer s.Close()
+
+	c := New(s.URL, WithTimeout(10*time.Millisecond))
+	err := c.Get("/").Do(nil)
+	require.Error(t, err)
+}
+
+func TestClientMarshaler(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/xml", r.Header.Get("Accept"))
+		w.Write([]byte("<testData><Message>xml</Message></testData>"))
+	}))
+	defer s.Close()
+
+	c := New(s.URL, WithMarshaler(&marshaler.XMLMarshaler{}))
+
+	res := &struct{ Message string }{}
+	err := c.Get("/").Do(res)
+	require.NoError(t, err)
+	require.Equal(t, "xml", res.Message)
+}
+
+func TestClientHTTPClient(t *testing.T) {
+	s := httptest.NewServer(testHandler())
+	defer s.Close()
+
+	calls := 0
+	hc := &testHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+		calls++
+		return http.DefaultClient.Do(req)
+	}}
+
+	err := New(s.URL, WithHTTPClient(hc)).Delete("items/1").Do(nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+}
+
+type testHTTPClient struct {
+	do func(req *http.Request) (*http.Response, error)
+}
+
+func (c *testHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return c.do(req)
+}
+
+func TestDoContextSendsRequestID(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&testData{Message: r.Header.Get(RequestIDHeader)})
+	}))
+	defer s.Close()
+
+	res := &testData{}
+	ctx := WithRequestID(context.Background(), "test-request-id")
+	err := NewRequest().URL(s.URL).DoContext(ctx, res)
+	require.NoError(t, err)
+	require.Equal(t, "test-request-id", res.Message)
+}
+
+func TestDoContextIsCancelled(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	err := NewRequest().URL(s.URL).DoContext(ctx, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -1,18 +1,37 @@
 package client
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// codedError is implemented by the errors with an error code like
+// "capo.ServerError".
+type codedError interface {
+	error
+	ErrorCode() string
+}
+
+var codedErrorType = reflect.TypeOf((*codedError)(nil)).Elem()
 
 // ServerError represents an error form the server side.
 type ServerError struct {
-	status int
-	data   []byte
+	status    int
+	header    http.Header
+	data      []byte
+	marshaler marshaler.Marshaler
 }
 
 // newServerError creates a new instance of server error.
-func newServerError(status int, data []byte) *ServerError {
+func newServerError(status int, header http.Header, data []byte, m marshaler.Marshaler) *ServerError {
 	return &ServerError{
-		status: status,
-		data:   data,
+		status:    status,
+		header:    header,
+		data:      data,
+		marshaler: m,
 	}
 }
 
@@ -20,7 +39,68 @@ func (e *ServerError) Error() string {
 	return fmt.Sprintf("%d", e.status)
 }
 
+// StatusCode returns the response status code.
+func (e *ServerError) StatusCode() int {
+	return e.status
+}
+
+// Header returns the response headers.
+func (e *ServerError) Header() http.Header {
+	return e.header
+}
+
+// Body returns the raw response body.
+func (e *ServerError) Body() []byte {
+	return e.data
+}
+
+// Code returns the error code in the response body. It is empty if the body
+// does not contain an error code.
+func (e *ServerError) Code() string {
+	res := &struct {
+		Code string `json:"code"`
+	}{}
+	if err := e.Read(res); err != nil {
+		return ""
+	}
+
+	return res.Code
+}
+
 // Read reads the server error response and set the data in the entity provided.
 func (e *ServerError) Read(entity interface{}) error {
-	return m.Unmarshal(e.data, entity)
+	return e.marshaler.Unmarshal(e.data, entity)
+}
+
+// As decodes the response body in the target if it is a pointer to an error
+// with an error code like "capo.ServerError". It allows to use "errors.As" with
+// the server errors.
+//
+//	var serverErr *capo.ServerError
+//	if errors.As(err, &serverErr) && serverErr.Code == capo.NotFoundErrorCode {
+//		...
+//	}
+func (e *ServerError) As(target interface{}) bool {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return false
+	}
+
+	// The target must be a pointer to a pointer of an error with a code.
+	errType := value.Type().Elem()
+	if errType.Kind() != reflect.Pointer || !errType.Implements(codedErrorType) {
+		return false
+	}
+
+	entity := reflect.New(errType.Elem())
+	if err := e.Read(entity.Interface()); err != nil {
+		return false
+	}
+
+	if entity.Interface().(codedError).ErrorCode() == "" {
+		return false
+	}
+
+	value.Elem().Set(entity)
+	return true
 }
@@ -0,0 +1,91 @@
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo"
+	"github.com/tonygcs/capo/client"
+)
+
+type testItem struct {
+	Name string `json:"name"`
+}
+
+// testCapoHandler creates a capo server that returns a not found error for the
+// missing items.
+func testCapoHandler() *capo.Server {
+	h := capo.New()
+	h.UseAfterAlways(capo.ErrorHandling)
+	h.Get("/items/{name}", func(ctx *capo.Context) error {
+		if ctx.Param("name") != "found" {
+			ctx.AddHeader("X-Error", "missing")
+			return capo.NewServerError(capo.NotFoundErrorCode, errors.New("missing item")).WithMessage("the item does not exist")
+		}
+
+		ctx.AddHeader("X-Item", "found")
+		ctx.Write(&testItem{Name: "found"})
+		return nil
+	})
+
+	return h
+}
+
+func TestServerErrorDecodesCapoError(t *testing.T) {
+	s := httptest.NewServer(testCapoHandler())
+	defer s.Close()
+
+	err := client.New(s.URL).Get("items/missing").Do(nil)
+	require.Error(t, err)
+
+	var serverErr *capo.ServerError
+	require.ErrorAs(t, err, &serverErr)
+	require.Equal(t, capo.NotFoundErrorCode, serverErr.Code)
+	require.Equal(t, "the item does not exist", serverErr.Message)
+	require.Equal(t, http.StatusNotFound, serverErr.HTTPStatus())
+
+	var clientErr *client.ServerError
+	require.ErrorAs(t, err, &clientErr)
+	require.Equal(t, http.StatusNotFound, clientErr.StatusCode())
+	require.Equal(t, "missing", clientErr.Header().Get("X-Error"))
+	require.Equal(t, capo.NotFoundErrorCode, clientErr.Code())
+}
+
+func TestServerErrorWithoutCode(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("bad gateway"))
+	}))
+	defer s.Close()
+
+	err := client.New(s.URL).Get("/").Do(nil)
+
+	var serverErr *capo.ServerError
+	require.False(t, errors.As(err, &serverErr))
+
+	var clientErr *client.ServerError
+	require.ErrorAs(t, err, &clientErr)
+	require.Equal(t, http.StatusBadGateway, clientErr.StatusCode())
+	require.Equal(t, "", clientErr.Code())
+	require.Equal(t, []byte("bad gateway"), clientErr.Body())
+}
+
+func TestDoWithResponse(t *testing.T) {
+	s := httptest.NewServer(testCapoHandler())
+	defer s.Close()
+
+	item := &testItem{}
+	res, err := client.New(s.URL).Get("items/found").DoWithResponse(item)
+	require.NoError(t, err)
+	require.Equal(t, "found", item.Name)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "found", res.Header.Get("X-Item"))
+	require.JSONEq(t, `{"name":"found"}`, string(res.Body))
+
+	res, err = client.New(s.URL).Get("items/missing").DoWithResponse(item)
+	require.Error(t, err)
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
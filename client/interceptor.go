@@ -0,0 +1,94 @@
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// Invoker performs the http request.
+type Invoker func(req *http.Request) (*http.Response, error)
+
+// Interceptor is a middleware for the client requests. It can modify the
+// request before calling the next invoker, inspect the response or return its
+// own response without calling the next invoker. The interceptors run on
+// every request attempt.
+type Interceptor func(req *http.Request, next Invoker) (*http.Response, error)
+
+// BeforeFunc is a handler that runs before each request attempt. The request is
+// cancelled if it returns an error.
+type BeforeFunc func(req *http.Request) error
+
+// AfterFunc is a handler that runs after each request attempt if the response
+// was received. The request fails if it returns an error. The response body
+// must not be consumed.
+type AfterFunc func(req *http.Request, res *http.Response, elapsed time.Duration) error
+
+// Use adds the interceptors for the client requests. They run in the order
+// they were added. It must not be called while the client is performing
+// requests.
+func (c *Client) Use(interceptors ...Interceptor) {
+	c.interceptors = append(c.interceptors, interceptors...)
+}
+
+// UseBefore adds the handlers that will run before each request attempt.
+func (c *Client) UseBefore(handlers ...BeforeFunc) {
+	for _, h := range handlers {
+		h := h
+		c.Use(func(req *http.Request, next Invoker) (*http.Response, error) {
+			if err := h(req); err != nil {
+				return nil, err
+			}
+
+			return next(req)
+		})
+	}
+}
+
+// UseAfter adds the handlers that will run after each request attempt if the
+// response was received.
+func (c *Client) UseAfter(handlers ...AfterFunc) {
+	for _, h := range handlers {
+		h := h
+		c.Use(func(req *http.Request, next Invoker) (*http.Response, error) {
+			start := time.Now()
+			res, err := next(req)
+			if err != nil {
+				return res, err
+			}
+
+			if err := h(req, res, time.Since(start)); err != nil {
+				closeBody(res.Body)
+				return nil, err
+			}
+
+			return res, nil
+		})
+	}
+}
+
+// WithInterceptors adds the interceptors for the client requests.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *Client) { c.Use(interceptors...) }
+}
+
+// invoker returns the invoker that runs the client interceptors and performs
+// the request.
+func (c *Client) invoker() Invoker {
+	invoke := func(req *http.Request) (*http.Response, error) {
+		return c.getBreakers().do(req, c.getHTTPClient().Do)
+	}
+
+	if c == nil {
+		return invoke
+	}
+
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor := c.interceptors[i]
+		next := invoke
+		invoke = func(req *http.Request) (*http.Response, error) {
+			return interceptor(req, next)
+		}
+	}
+
+	return invoke
+}
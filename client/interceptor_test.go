@@ -0,0 +1,115 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterceptorsOrder(t *testing.T) {
+	s := httptest.NewServer(testHandler())
+	defer s.Close()
+	c := New(s.URL)
+
+	var calls []string
+	interceptor := func(name string) Interceptor {
+		return func(req *http.Request, next Invoker) (*http.Response, error) {
+			calls = append(calls, "before "+name)
+			res, err := next(req)
+			calls = append(calls, "after "+name)
+			return res, err
+		}
+	}
+	c.Use(interceptor("first"), interceptor("second"))
+
+	require.NoError(t, c.Get("/").Do(nil))
+	require.Equal(t, []string{"before first", "before second", "after second", "after first"}, calls)
+}
+
+func TestInterceptorModifiesRequest(t *testing.T) {
+	s := httptest.NewServer(testHandler())
+	defer s.Close()
+	c := New(s.URL)
+	c.UseBefore(func(req *http.Request) error {
+		req.Header.Set("X-Service", "token")
+		return nil
+	})
+
+	res := &testData{}
+	require.NoError(t, c.Get("/").Do(res))
+	require.Equal(t, "GET / token ", res.Message)
+}
+
+func TestInterceptorCancelsRequest(t *testing.T) {
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer s.Close()
+
+	errNoToken := errors.New("no token")
+	c := New(s.URL)
+	c.UseBefore(func(req *http.Request) error { return errNoToken })
+
+	err := c.Get("/").Do(nil)
+	require.ErrorIs(t, err, errNoToken)
+	require.Equal(t, int32(0), atomic.LoadInt32(&requests))
+}
+
+func TestInterceptorShortCircuits(t *testing.T) {
+	c := New("http://localhost:0", WithInterceptors(func(req *http.Request, next Invoker) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"msg":"cached"}`)),
+		}, nil
+	}))
+
+	res := &testData{}
+	require.NoError(t, c.Get("/").Do(res))
+	require.Equal(t, "cached", res.Message)
+}
+
+func TestInterceptorAfterSeesResponse(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	var status int
+	var elapsed time.Duration
+	c := New(s.URL)
+	c.UseAfter(func(req *http.Request, res *http.Response, d time.Duration) error {
+		status = res.StatusCode
+		elapsed = d
+		return nil
+	})
+
+	require.NoError(t, c.Get("/").Do(nil))
+	require.Equal(t, http.StatusAccepted, status)
+	require.GreaterOrEqual(t, elapsed, 10*time.Millisecond)
+}
+
+func TestInterceptorsRunOnEveryAttempt(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(failingHandler(http.StatusServiceUnavailable, 2, &attempts))
+	defer s.Close()
+
+	var calls int32
+	c := New(s.URL, WithRetryPolicy(testRetryPolicy()))
+	c.UseBefore(func(req *http.Request) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+
+	require.NoError(t, c.Get("/").Do(nil))
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
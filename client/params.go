@@ -0,0 +1,182 @@
+package client
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Query adds a query parameter to the request url.
+func (r *Request) Query(key string, value string) *Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.query.Add(key, value)
+	return r
+}
+
+// QueryStruct adds the fields of the struct provided tagged with "query" as
+// query parameters of the request url. The fields with the "omitempty" option
+// are skipped if they have the zero value, e.g.
+//
+//	type Filter struct {
+//		Name  string   `query:"name,omitempty"`
+//		Tags  []string `query:"tag"`
+//		Limit int      `query:"limit"`
+//	}
+func (r *Request) QueryStruct(v interface{}) *Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := encodeQuery(r.query, reflect.ValueOf(v)); err != nil {
+		r.err = fmt.Errorf("invalid query struct :: %w", err)
+	}
+
+	return r
+}
+
+// PathParam sets the value of a parameter in the url path template. e.g. the
+// "id" parameter in "/users/{id}".
+func (r *Request) PathParam(name string, value interface{}) *Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pathParams[name] = fmt.Sprint(value)
+	return r
+}
+
+// buildURL returns the request url with the path parameters and the query
+// parameters set.
+func (r *Request) buildURL() (string, error) {
+	u, err := url.Parse(r.url)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, r.relativePath)
+
+	// Replace the path parameters escaping them, so every parameter is a
+	// single path segment.
+	if len(r.pathParams) > 0 {
+		segments := strings.Split(u.Path, "/")
+		escaped := make([]string, len(segments))
+		for i, segment := range segments {
+			for name, value := range r.pathParams {
+				segment = strings.ReplaceAll(segment, "{"+name+"}", value)
+			}
+
+			segments[i] = segment
+			escaped[i] = url.PathEscape(segment)
+		}
+
+		u.Path = strings.Join(segments, "/")
+		u.RawPath = strings.Join(escaped, "/")
+	}
+
+	if len(r.query) > 0 {
+		query := u.Query()
+		for key, values := range r.query {
+			query[key] = append(query[key], values...)
+		}
+
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String(), nil
+}
+
+// encodeQuery adds the struct fields tagged with "query" in the values
+// provided.
+func encodeQuery(values url.Values, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot encode %s as query", v.Kind())
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		// Encode the embedded structs fields.
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := encodeQuery(values, fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("query")
+		if !ok {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if opts == "omitempty" && fieldValue.IsZero() {
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Slice && !field.Type.Implements(textMarshalerType) {
+			for j := 0; j < fieldValue.Len(); j++ {
+				value, err := formatQueryValue(fieldValue.Index(j))
+				if err != nil {
+					return fmt.Errorf("invalid field %s :: %w", name, err)
+				}
+				values.Add(name, value)
+			}
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() {
+			continue
+		}
+
+		value, err := formatQueryValue(fieldValue)
+		if err != nil {
+			return fmt.Errorf("invalid field %s :: %w", name, err)
+		}
+		values.Add(name, value)
+	}
+
+	return nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// formatQueryValue returns the string representation of the value provided.
+func formatQueryValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface()), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// urlHandler returns the escaped request path and the query in the response
+// message.
+func urlHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"msg":"` + r.URL.EscapedPath() + "?" + r.URL.RawQuery + `"}`))
+	}
+}
+
+func TestRequestQuery(t *testing.T) {
+	s := httptest.NewServer(urlHandler())
+	defer s.Close()
+
+	res := &testData{}
+	err := New(s.URL+"?a=1").Get("items").Query("b", "2").Query("b", "3").Do(res)
+	require.NoError(t, err)
+	require.Equal(t, "/items?a=1&b=2&b=3", res.Message)
+}
+
+type testFilter struct {
+	Name    string        `query:"name,omitempty"`
+	Tags    []string      `query:"tag"`
+	Limit   int           `query:"limit"`
+	Since   *time.Time    `query:"since"`
+	Timeout time.Duration `query:"timeout"`
+	Ignored string
+}
+
+func TestRequestQueryStruct(t *testing.T) {
+	s := httptest.NewServer(urlHandler())
+	defer s.Close()
+
+	since := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := &testFilter{Tags: []string{"a", "b"}, Limit: 10, Since: &since, Timeout: time.Second, Ignored: "x"}
+
+	res := &testData{}
+	err := New(s.URL).Get("items").QueryStruct(filter).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, "/items?limit=10&since=2022-01-01T00%3A00%3A00Z&tag=a&tag=b&timeout=1s", res.Message)
+}
+
+func TestRequestQueryStructInvalid(t *testing.T) {
+	err := New("http://localhost").Get("items").QueryStruct("invalid").Do(nil)
+	require.Error(t, err)
+}
+
+func TestRequestPathParams(t *testing.T) {
+	s := httptest.NewServer(urlHandler())
+	defer s.Close()
+
+	res := &testData{}
+	err := New(s.URL+"/api").
+		Get("users/{id}/items/{name}").
+		PathParam("id", 42).
+		PathParam("name", "a/b c").
+		Do(res)
+	require.NoError(t, err)
+	require.Equal(t, "/api/users/42/items/a%2Fb%20c?", res.Message)
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// PoolConfig is the connection pool configuration of a client.
+type PoolConfig struct {
+	// MaxIdleConns is the maximum number of idle connections to all the hosts.
+	// Zero means no limit.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections to every
+	// host. Zero means the "net/http" default, 2.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost is the maximum number of connections to every host,
+	// including the active ones. Zero means no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout is the time an idle connection is kept. Zero means no
+	// limit.
+	IdleConnTimeout time.Duration
+}
+
+// WithPool sets the connection pool configuration of the client. It creates a
+// new http client for the client requests, so it replaces the http client set
+// with "WithHTTPClient".
+func WithPool(config PoolConfig) Option {
+	return func(c *Client) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConns = config.MaxIdleConns
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+		transport.MaxConnsPerHost = config.MaxConnsPerHost
+		transport.IdleConnTimeout = config.IdleConnTimeout
+
+		c.httpClient = &http.Client{Transport: transport}
+	}
+}
+
+// WithMaxResponseSize sets the maximum size in bytes of the response bodies
+// read by the client. The requests with a bigger response fail with
+// "ErrResponseTooLarge". The streamed responses are not limited.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *Client) { c.maxResponseSize = size }
+}
+
+// CloseIdleConnections closes the idle connections of the client http client.
+func (c *Client) CloseIdleConnections() {
+	if closer, ok := c.getHTTPClient().(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer s.Close()
+
+	err := New(s.URL, WithMaxResponseSize(99)).Get("/").Do(nil)
+	require.ErrorIs(t, err, ErrResponseTooLarge)
+
+	_, err = New(s.URL, WithMaxResponseSize(100)).Get("/").DoWithResponse(nil)
+	require.NoError(t, err)
+}
+
+func TestConnectionsAreReused(t *testing.T) {
+	var conns int32
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code":"INTERNAL_ERROR"}`))
+		case "/invalid":
+			w.Write([]byte("invalid"))
+		case "/large":
+			w.Write([]byte(strings.Repeat("a", 1000)))
+		case "/items":
+			w.Write([]byte("{\"msg\":\"first\"}\n{\"msg\":\"second\"}\n"))
+		default:
+			w.Write([]byte(`{"msg":"ok"}`))
+		}
+	}))
+	s.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	s.Start()
+	defer s.Close()
+
+	c := New(s.URL, WithPool(PoolConfig{MaxIdleConnsPerHost: 1}), WithMaxResponseSize(100))
+	defer c.CloseIdleConnections()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Get("/").Do(&testData{}))
+		require.Error(t, c.Get("/error").Do(nil))
+		require.Error(t, c.Get("/invalid").Do(&testData{}))
+		require.ErrorIs(t, c.Get("/large").Do(nil), ErrResponseTooLarge)
+
+		// The stream is closed before reading all the items.
+		res, err := c.Get("/items").DoStream()
+		require.NoError(t, err)
+		require.NoError(t, res.Decoder().Next(&testData{}))
+		require.NoError(t, res.Close())
+	}
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&conns))
+}
+
+func TestRequestsDoNotLeakGoroutines(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write([]byte(`{"msg":"ok"}`))
+	}))
+
+	before := runtime.NumGoroutine()
+
+	c := New(s.URL, WithPool(PoolConfig{}), WithRetryPolicy(testRetryPolicy()))
+	for i := 0; i < 20; i++ {
+		require.NoError(t, c.Get("/").Do(&testData{}))
+		require.Error(t, c.Get("/error").Do(nil))
+	}
+
+	c.CloseIdleConnections()
+	s.Close()
+
+	require.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= before
+	}, time.Second, 10*time.Millisecond)
+}
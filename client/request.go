@@ -2,51 +2,105 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"path"
 	"sync"
 
 	"github.com/tonygcs/capo/marshaler"
 )
 
-var (
-	client httpClient
-	m      marshaler.Marshaler
-)
+var defaultHTTPClient httpClient
 
 func init() {
-	// Set default http client and marshaler.
-	client = &http.Client{}
-	m = marshaler.GetMarshaler()
+	// Set default http client.
+	defaultHTTPClient = &http.Client{}
 }
 
-// SetHTTPClient sets the http client that will request the server.
+// SetHTTPClient sets the http client that will request the server. It is used
+// by the requests without a client and the clients without their own http
+// client.
 func SetHTTPClient(c httpClient) {
-	client = c
+	defaultHTTPClient = c
 }
 
 type httpClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// ErrResponseTooLarge is returned when the response body is bigger than the
+// maximum response size of the client.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// maxDrainSize is the maximum number of bytes read from a response body before
+// closing it to reuse the connection.
+const maxDrainSize = 256 << 10
+
+// RequestIDHeader is the header that propagates the request id to the
+// downstream services.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of the context provided with the request id
+// that will be sent in the requests performed with it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// contextWrapper is implemented by the contexts that wrap a standard context
+// like "capo.Context".
+type contextWrapper interface {
+	Context() context.Context
+}
+
+// requestIDProvider is implemented by the contexts that know the request id
+// like "capo.Context".
+type requestIDProvider interface {
+	RequestID() string
+}
+
+// requestID returns the request id in the context provided.
+func requestID(ctx context.Context) string {
+	if p, ok := ctx.(requestIDProvider); ok {
+		if id := p.RequestID(); id != "" {
+			return id
+		}
+	}
+
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
 // Request is a http request.
 type Request struct {
 	mu sync.Mutex
 
+	client       *Client
 	headers      map[string]string
 	method       string
 	url          string
 	relativePath string
 	data         interface{}
+	marshaler    marshaler.Marshaler
+	retryPolicy  *RetryPolicy
+	query        url.Values
+	pathParams   map[string]string
+	form         url.Values
+	multipart    *multipartBody
+	stream       *payload
+	err          error
 }
 
 // NewRequest returns a new http request instance.
 func NewRequest() *Request {
 	return &Request{
 		headers:      make(map[string]string),
+		query:        url.Values{},
+		pathParams:   make(map[string]string),
 		method:       http.MethodGet,
 		url:          "/",
 		relativePath: "",
@@ -81,78 +135,208 @@ func (r *Request) Method(method string) *Request {
 	return r
 }
 
-// Data sets the request data that will be sent.
+// Data sets the request data that will be sent. It is marshaled with the
+// request marshaler.
 func (r *Request) Data(data interface{}) *Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resetBody()
 	r.data = data
 	return r
 }
 
+// Marshaler sets the marshaler for the request and response bodies. If it is
+// not set, the request uses the client marshaler or the default marshaler of
+// the "marshaler" package.
+func (r *Request) Marshaler(m marshaler.Marshaler) *Request {
+	r.marshaler = m
+	return r
+}
+
+// Retry sets the retry policy for the request. It overrides the client retry
+// policy.
+func (r *Request) Retry(policy *RetryPolicy) *Request {
+	r.retryPolicy = policy
+	return r
+}
+
 // Do performs the http request.
 func (r *Request) Do(response interface{}) error {
-	// Create request body if it is needed.
-	var body io.Reader
-	if r.data != nil {
-		bodyContent, err := m.Marshal(r.data)
+	return r.DoContext(context.Background(), response)
+}
+
+// DoContext performs the http request with the context provided. The request
+// is cancelled if the context is done. The context can be a "capo.Context", so
+// the request uses its standard context and the request id of the incoming
+// request is sent in the "X-Request-ID" header.
+func (r *Request) DoContext(ctx context.Context, response interface{}) error {
+	_, err := r.DoContextWithResponse(ctx, response)
+	return err
+}
+
+// DoWithResponse performs the http request and returns the response status,
+// headers and body.
+func (r *Request) DoWithResponse(response interface{}) (*Response, error) {
+	return r.DoContextWithResponse(context.Background(), response)
+}
+
+// DoContextWithResponse performs the http request with the context provided
+// and returns the response status, headers and body. The response is nil if
+// the request could not be performed.
+func (r *Request) DoContextWithResponse(ctx context.Context, response interface{}) (*Response, error) {
+	res, m, cancel, err := r.perform(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	defer closeBody(res.Body)
+
+	data, err := readBody(res.Body, r.client.getMaxResponseSize())
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the response body :: %w", err)
+	}
+
+	result := &Response{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       data,
+	}
+
+	// Handle error from server side.
+	if !isSuccess(res.StatusCode) {
+		return result, newServerError(res.StatusCode, res.Header, data, m)
+	}
+
+	// Read response if it is needed. There is nothing to read if the response
+	// has no body.
+	if response != nil && len(data) > 0 {
+		err = m.Unmarshal(data, response)
 		if err != nil {
-			return fmt.Errorf("cannot marshal the request body :: %w", err)
+			return result, fmt.Errorf("invalid response format :: %w", err)
 		}
+	}
+
+	return result, nil
+}
 
-		body = bytes.NewReader(bodyContent)
+// perform sends the request and returns the response with the marshaler used
+// and the function that releases the request context. The function must be
+// called once the response body is read.
+func (r *Request) perform(ctx context.Context) (*http.Response, marshaler.Marshaler, context.CancelFunc, error) {
+	if r.err != nil {
+		return nil, nil, nil, r.err
 	}
 
-	// Create request instance.
-	url, err := joinPaths(r.url, r.relativePath)
-	if err != nil {
-		return fmt.Errorf("invalid url format :: %w", err)
+	m := r.marshaler
+	if m == nil {
+		m = r.client.getMarshaler()
+	}
+
+	reqID := requestID(ctx)
+	if w, ok := ctx.(contextWrapper); ok {
+		ctx = w.Context()
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if timeout := r.client.getTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
 	}
 
-	req, err := http.NewRequest(r.method, url, body)
+	// Create request body if it is needed. It is encoded once and sent on
+	// every attempt.
+	body, err := r.encodeBody(m)
 	if err != nil {
-		return fmt.Errorf("cannot create the request entity :: %w", err)
+		cancel()
+		return nil, nil, nil, fmt.Errorf("cannot marshal the request body :: %w", err)
 	}
 
-	// Set headers.
-	for key, value := range r.headers {
-		req.Header.Add(key, value)
+	// Create request url.
+	url, err := r.buildURL()
+	if err != nil {
+		cancel()
+		return nil, nil, nil, fmt.Errorf("invalid url format :: %w", err)
 	}
 
 	// Send request.
-	res, err := client.Do(req)
+	res, err := r.send(ctx, func() (*http.Request, error) {
+		return r.newHTTPRequest(ctx, url, body, m, reqID)
+	})
 	if err != nil {
-		return fmt.Errorf("cannot perform the http request :: %w", err)
+		cancel()
+		return nil, nil, nil, fmt.Errorf("cannot perform the http request :: %w", err)
 	}
 
-	// Handle error from server side.
-	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
-		data, err := io.ReadAll(res.Body)
-		if err != nil {
-			return fmt.Errorf("cannot read the error response body :: %w", err)
-		}
+	return res, m, cancel, nil
+}
 
-		return newServerError(res.StatusCode, data)
+// readBody reads the response body. It fails with "ErrResponseTooLarge" if the
+// body is bigger than the maximum size provided. Zero means no limit.
+func readBody(body io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return io.ReadAll(body)
 	}
 
-	// Read response if it is needed.
-	if response != nil {
-		data, err := io.ReadAll(res.Body)
-		if err != nil {
-			return fmt.Errorf("cannot read the response body :: %w", err)
-		}
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
 
-		err = m.Unmarshal(data, response)
-		if err != nil {
-			return fmt.Errorf("invalid response format :: %w", err)
-		}
+	if int64(len(data)) > maxSize {
+		return nil, ErrResponseTooLarge
 	}
 
-	return nil
+	return data, nil
+}
+
+// closeBody drains and closes the response body, so the connection can be
+// reused. The body is not drained if it is too large.
+func closeBody(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrainSize))
+	body.Close()
 }
 
-func joinPaths(path1 string, path2 string) (string, error) {
-	u, err := url.Parse(path1)
+// isSuccess checks if the status code provided is a 2xx one.
+func isSuccess(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+// newHTTPRequest creates the http request entity with the body provided.
+func (r *Request) newHTTPRequest(ctx context.Context, url string, body *payload, m marshaler.Marshaler, reqID string) (*http.Request, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = body.reader
+		if bodyReader == nil {
+			bodyReader = bytes.NewReader(body.data)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, url, bodyReader)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("cannot create the request entity :: %w", err)
 	}
-	u.Path = path.Join(u.Path, path2)
-	return u.String(), nil
+
+	if body != nil && body.reader != nil && body.length > 0 {
+		req.ContentLength = body.length
+	}
+
+	// Set the marshaler and request id headers. They can be overridden by the
+	// request headers.
+	req.Header.Set("Accept", m.ContentTypeHeader())
+	if body != nil {
+		req.Header.Set("Content-Type", body.contentType)
+	}
+	if reqID != "" {
+		req.Header.Set(RequestIDHeader, reqID)
+	}
+
+	// Set headers. The request headers take precedence over the client ones.
+	for key, value := range r.client.getHeaders() {
+		req.Header.Set(key, value)
+	}
+	for key, value := range r.headers {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
 }
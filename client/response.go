@@ -0,0 +1,13 @@
+package client
+
+import "net/http"
+
+// Response is the http response of a request.
+type Response struct {
+	// StatusCode is the response status code.
+	StatusCode int
+	// Header contains the response headers.
+	Header http.Header
+	// Body is the raw response body.
+	Body []byte
+}
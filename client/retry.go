@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy is the configuration to retry the failed requests. The requests
+// are retried on transient connection errors (e.g. refused connections or
+// timeouts) and on the response status codes in "RetryOn". Only the idempotent
+// methods are retried unless the idempotency key header is set in the request.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time to wait between attempts. It also limits
+	// the time set in the "Retry-After" response header.
+	MaxBackoff time.Duration
+	// Multiplier is the factor that increases the backoff on every retry.
+	Multiplier float64
+	// Jitter is the random factor, between 0 and 1, applied to the backoff.
+	Jitter float64
+	// RetryOn are the response status codes that will be retried.
+	RetryOn []int
+	// IdempotencyKeyHeader is the header that makes the non idempotent
+	// requests retryable if it is set (e.g. "Idempotency-Key").
+	IdempotencyKeyHeader string
+}
+
+// DefaultRetryPolicy returns a retry policy with 3 attempts and exponential
+// backoff that retries the 429, 502, 503 and 504 status codes.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryOn: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		IdempotencyKeyHeader: "Idempotency-Key",
+	}
+}
+
+// WithRetryPolicy sets the retry policy for the client requests.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Client) { c.retryPolicy = policy }
+}
+
+// idempotentMethods are the HTTP methods that can be retried safely.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// canRetry checks if the request can be retried.
+func (p *RetryPolicy) canRetry(req *http.Request) bool {
+	if p == nil || p.MaxAttempts <= 1 {
+		return false
+	}
+
+	if idempotentMethods[req.Method] {
+		return true
+	}
+
+	return p.IdempotencyKeyHeader != "" && req.Header.Get(p.IdempotencyKeyHeader) != ""
+}
+
+// shouldRetry checks if the attempt result must be retried.
+func (p *RetryPolicy) shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	// The request was cancelled by the caller.
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return isTransient(err)
+	}
+
+	for _, status := range p.RetryOn {
+		if res.StatusCode == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTransient checks if the request error can be solved by retrying it. The
+// cancelled requests and the errors that are not network errors (e.g. invalid
+// URLs or certificate errors) are not transient.
+func isTransient(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// The connection was closed by the server.
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// backoff returns the time to wait before the retry provided, starting with 1.
+// The "Retry-After" response header takes precedence if it exists, but it
+// cannot exceed the maximum backoff.
+func (p *RetryPolicy) backoff(retry int, res *http.Response) time.Duration {
+	if wait, ok := retryAfter(res, time.Now()); ok {
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			wait = p.MaxBackoff
+		}
+		return wait
+	}
+
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	wait := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		wait += wait * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(wait)
+}
+
+// retryAfter returns the time to wait set in the "Retry-After" response header.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// send performs the request built by the function provided and retries it
+// according to the retry policy. The function is called on every attempt, so
+// the request body is rebuilt.
+func (r *Request) send(ctx context.Context, build func() (*http.Request, error)) (*http.Response, error) {
+	policy := r.retryPolicy
+	if policy == nil {
+		policy = r.client.getRetryPolicy()
+	}
+
+	// The streamed bodies cannot be sent again.
+	if r.stream != nil {
+		policy = nil
+	}
+
+	invoke := r.client.invoker()
+
+	for attempt := 1; ; attempt++ {
+		req, err := build()
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := invoke(req)
+
+		// The circuit breaker rejections are not retried.
+		if errors.Is(err, ErrCircuitOpen) {
+			return nil, err
+		}
+
+		if attempt >= policy.maxAttempts() || !policy.canRetry(req) || !policy.shouldRetry(ctx, res, err) {
+			return res, err
+		}
+
+		wait := policy.backoff(attempt, res)
+
+		// Release the connection of the discarded response.
+		if res != nil {
+			closeBody(res.Body)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// maxAttempts returns the maximum number of attempts of the policy.
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testRetryPolicy returns a retry policy without waits between attempts.
+func testRetryPolicy() *RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.InitialBackoff = time.Millisecond
+	p.Jitter = 0
+	return p
+}
+
+// failingHandler fails with the status provided until the number of failures
+// is reached. The request bodies are echoed back.
+func failingHandler(status int, failures int32, attempts *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(attempts, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}
+}
+
+func TestRetryTransientStatus(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(failingHandler(http.StatusServiceUnavailable, 2, &attempts))
+	defer s.Close()
+	c := New(s.URL, WithRetryPolicy(testRetryPolicy()))
+
+	res := &testData{}
+	err := c.Put("/").Data(&testData{Message: "retried"}).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, "retried", res.Message)
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(failingHandler(http.StatusBadGateway, 5, &attempts))
+	defer s.Close()
+	c := New(s.URL, WithRetryPolicy(testRetryPolicy()))
+
+	err := c.Get("/").Do(nil)
+	require.Error(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryIgnoresOtherStatus(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(failingHandler(http.StatusInternalServerError, 1, &attempts))
+	defer s.Close()
+	c := New(s.URL, WithRetryPolicy(testRetryPolicy()))
+
+	err := c.Get("/").Do(nil)
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryNonIdempotentMethod(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(failingHandler(http.StatusServiceUnavailable, 1, &attempts))
+	defer s.Close()
+	c := New(s.URL, WithRetryPolicy(testRetryPolicy()))
+
+	err := c.Post("/").Data(&testData{}).Do(nil)
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryNonIdempotentMethodWithIdempotencyKey(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(failingHandler(http.StatusServiceUnavailable, 1, &attempts))
+	defer s.Close()
+	c := New(s.URL, WithRetryPolicy(testRetryPolicy()))
+
+	res := &testData{}
+	err := c.Post("/").
+		AddHeader("Idempotency-Key", "key").
+		Data(&testData{Message: "created"}).
+		Do(res)
+	require.NoError(t, err)
+	require.Equal(t, "created", res.Message)
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryRequestPolicyOverridesClient(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(failingHandler(http.StatusServiceUnavailable, 1, &attempts))
+	defer s.Close()
+	c := New(s.URL)
+
+	err := c.Get("/").Retry(testRetryPolicy()).Do(nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryConnectionError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := s.URL
+	s.Close()
+
+	var attempts int32
+	c := New(url, WithRetryPolicy(testRetryPolicy()), WithHTTPClient(&countingClient{attempts: &attempts}))
+
+	err := c.Get("/").Do(nil)
+	require.Error(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryNonTransientErrors(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "certificate", url: s.URL},
+		{name: "invalid url", url: "unknown://" + s.Listener.Addr().String()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int32
+			c := New(test.url, WithRetryPolicy(testRetryPolicy()), WithHTTPClient(&countingClient{attempts: &attempts}))
+
+			err := c.Get("/").Do(nil)
+			require.Error(t, err)
+			require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestRetryAfterIsLimitedByMaxBackoff(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer s.Close()
+
+	policy := testRetryPolicy()
+	policy.MaxBackoff = 10 * time.Millisecond
+	c := New(s.URL, WithRetryPolicy(policy))
+
+	start := time.Now()
+	err := c.Get("/").Do(nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryStopsOnContextCancel(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer s.Close()
+
+	c := New(s.URL, WithRetryPolicy(testRetryPolicy()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Get("/").DoContext(ctx, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		wait  time.Duration
+		ok    bool
+	}{
+		{name: "seconds", value: "3", wait: 3 * time.Second, ok: true},
+		{name: "date", value: now.Add(time.Minute).Format(http.TimeFormat), wait: time.Minute, ok: true},
+		{name: "past date", value: now.Add(-time.Minute).Format(http.TimeFormat), wait: 0, ok: true},
+		{name: "invalid", value: "soon", ok: false},
+		{name: "empty", value: "", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			res.Header.Set("Retry-After", test.value)
+
+			wait, ok := retryAfter(res, now)
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.wait, wait)
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	require.Equal(t, 100*time.Millisecond, p.backoff(1, nil))
+	require.Equal(t, 200*time.Millisecond, p.backoff(2, nil))
+	require.Equal(t, 400*time.Millisecond, p.backoff(3, nil))
+	require.Equal(t, time.Second, p.backoff(5, nil))
+
+	p.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		wait := p.backoff(1, nil)
+		require.GreaterOrEqual(t, wait, 50*time.Millisecond)
+		require.LessOrEqual(t, wait, 150*time.Millisecond)
+	}
+}
+
+// countingClient is a http client that counts the performed requests.
+type countingClient struct {
+	attempts *int32
+}
+
+func (c *countingClient) Do(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(c.attempts, 1)
+	return http.DefaultClient.Do(req)
+}
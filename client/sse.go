@@ -0,0 +1,141 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// Event is a Server-Sent Event.
+type Event struct {
+	// ID is the event id. It is the id of the last event if the event does not
+	// set it.
+	ID string
+	// Event is the event name. It is "message" if the event does not set it.
+	Event string
+	// Data is the event data.
+	Data []byte
+	// Retry is the last reconnection time set by the server. It is zero if the
+	// server does not set it.
+	Retry time.Duration
+
+	marshaler marshaler.Marshaler
+}
+
+// Read reads the event data with the marshaler of the reader and sets it in the
+// entity provided.
+func (e *Event) Read(entity interface{}) error {
+	return e.marshaler.Unmarshal(e.Data, entity)
+}
+
+// EventReader reads the Server-Sent Events of a stream.
+type EventReader struct {
+	r           *bufio.Reader
+	m           marshaler.Marshaler
+	lastEventID string
+	retry       time.Duration
+}
+
+// EventReaderOption is an option to configure an event reader.
+type EventReaderOption func(*EventReader)
+
+// WithEventMarshaler sets the marshaler that reads the events data.
+func WithEventMarshaler(m marshaler.Marshaler) EventReaderOption {
+	return func(r *EventReader) { r.m = m }
+}
+
+// NewEventReader returns a reader for the Server-Sent Events stream provided.
+// The events data is read with the default marshaler of the "marshaler"
+// package unless another one is set.
+func NewEventReader(r io.Reader, opts ...EventReaderOption) *EventReader {
+	reader := &EventReader{r: bufio.NewReader(r), m: marshaler.GetMarshaler()}
+	for _, opt := range opts {
+		opt(reader)
+	}
+
+	return reader
+}
+
+// Events returns the reader of the Server-Sent Events of the response. The
+// events data is read with the request marshaler unless another one is set.
+func (r *StreamResponse) Events(opts ...EventReaderOption) *EventReader {
+	if r.marshaler != nil {
+		opts = append([]EventReaderOption{WithEventMarshaler(r.marshaler)}, opts...)
+	}
+
+	return NewEventReader(r.Body, opts...)
+}
+
+// LastEventID returns the id of the last event read. It must be sent in the
+// "Last-Event-ID" header to resume the stream.
+func (r *EventReader) LastEventID() string {
+	return r.lastEventID
+}
+
+// Next returns the next event of the stream. The comments are ignored. It
+// returns "io.EOF" when the stream ends.
+func (r *EventReader) Next() (*Event, error) {
+	name := ""
+	var data [][]byte
+
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			// The stream ends without the last empty line.
+			if err == io.EOF && data != nil {
+				return r.event(name, data), nil
+			}
+			return nil, err
+		}
+
+		line = bytes.TrimRight(line, "\r\n")
+
+		// An empty line dispatches the event. The events without data are
+		// discarded.
+		if len(line) == 0 {
+			if data != nil {
+				return r.event(name, data), nil
+			}
+
+			name = ""
+			continue
+		}
+
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+
+		switch string(field) {
+		case "event":
+			name = string(value)
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !bytes.Contains(value, []byte{0}) {
+				r.lastEventID = string(value)
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(string(value)); err == nil {
+				r.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// event returns the event with the name and data lines provided.
+func (r *EventReader) event(name string, data [][]byte) *Event {
+	if name == "" {
+		name = "message"
+	}
+
+	return &Event{
+		ID:        r.lastEventID,
+		Event:     name,
+		Data:      bytes.Join(data, []byte("\n")),
+		Retry:     r.retry,
+		marshaler: r.m,
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+func TestEventReader(t *testing.T) {
+	stream := ": comment\n" +
+		"retry: 1000\n\n" +
+		"event: update\nid: 1\ndata: {\"msg\":\"first\"}\n\n" +
+		"data: multi\r\ndata: line\r\n\r\n" +
+		"event: ignored\n\n" +
+		"id: 3\ndata:last"
+
+	r := NewEventReader(strings.NewReader(stream))
+
+	event, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, "update", event.Event)
+	require.Equal(t, "1", event.ID)
+	require.Equal(t, time.Second, event.Retry)
+
+	data := &testData{}
+	require.NoError(t, event.Read(data))
+	require.Equal(t, "first", data.Message)
+
+	event, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, "message", event.Event)
+	require.Equal(t, "1", event.ID)
+	require.Equal(t, "multi\nline", string(event.Data))
+
+	event, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, "message", event.Event)
+	require.Equal(t, "3", event.ID)
+	require.Equal(t, "last", string(event.Data))
+
+	_, err = r.Next()
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, "3", r.LastEventID())
+}
+
+func TestEventReaderMarshaler(t *testing.T) {
+	stream := "data: <testData><Message>xml</Message></testData>\n\n"
+
+	event, err := NewEventReader(strings.NewReader(stream), WithEventMarshaler(&marshaler.XMLMarshaler{})).Next()
+	require.NoError(t, err)
+
+	data := &testData{}
+	require.NoError(t, event.Read(data))
+	require.Equal(t, "xml", data.Message)
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// StreamResponse is the http response of a request with the body not read yet.
+// The body must be closed.
+type StreamResponse struct {
+	// StatusCode is the response status code.
+	StatusCode int
+	// Header contains the response headers.
+	Header http.Header
+	// Body is the response body.
+	Body io.ReadCloser
+
+	marshaler marshaler.Marshaler
+}
+
+// Decoder returns the decoder for the items of a NDJSON or JSON sequence
+// response body.
+func (r *StreamResponse) Decoder() *StreamDecoder {
+	return NewStreamDecoder(r.Body)
+}
+
+// Close closes the response body.
+func (r *StreamResponse) Close() error {
+	return r.Body.Close()
+}
+
+// DoStream performs the http request and returns the response without reading
+// its body.
+func (r *Request) DoStream() (*StreamResponse, error) {
+	return r.DoStreamContext(context.Background())
+}
+
+// DoStreamContext performs the http request with the context provided and
+// returns the response without reading its body. The error responses are read
+// and returned as a "ServerError".
+func (r *Request) DoStreamContext(ctx context.Context) (*StreamResponse, error) {
+	res, m, cancel, err := r.perform(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle error from server side.
+	if !isSuccess(res.StatusCode) {
+		defer cancel()
+		defer closeBody(res.Body)
+
+		data, err := readBody(res.Body, r.client.getMaxResponseSize())
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the error response body :: %w", err)
+		}
+
+		return nil, newServerError(res.StatusCode, res.Header, data, m)
+	}
+
+	return &StreamResponse{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       &cancelReadCloser{ReadCloser: res.Body, cancel: cancel},
+		marshaler:  m,
+	}, nil
+}
+
+// cancelReadCloser drains the body and releases the request context when the
+// body is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelReadCloser) Close() error {
+	io.Copy(io.Discard, io.LimitReader(c.ReadCloser, maxDrainSize))
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// recordSeparator is the prefix of the JSON sequence records (RFC 7464).
+const recordSeparator = "\x1e"
+
+// StreamDecoder decodes the items of a NDJSON or JSON sequence stream.
+type StreamDecoder struct {
+	r *bufio.Reader
+}
+
+// NewStreamDecoder returns a decoder for the NDJSON or JSON sequence stream
+// provided.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{r: bufio.NewReader(r)}
+}
+
+// Next decodes the next item of the stream in the entity provided. It returns
+// "io.EOF" when there are no more items.
+func (d *StreamDecoder) Next(entity interface{}) error {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return err
+		}
+
+		line = bytes.TrimSpace(bytes.TrimLeft(line, recordSeparator))
+		if len(line) == 0 {
+			if err == io.EOF {
+				return io.EOF
+			}
+			continue
+		}
+
+		if err := json.Unmarshal(line, entity); err != nil {
+			return fmt.Errorf("invalid stream item :: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestStreamBody(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, int64(7), r.ContentLength)
+		require.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "content", string(body))
+	}))
+	defer s.Close()
+
+	err := New(s.URL).Put("/").
+		Body(io.LimitReader(strings.NewReader("content"), 7), 7).
+		AddHeader("Content-Type", "text/plain").
+		Do(nil)
+	require.NoError(t, err)
+}
+
+func TestRequestStreamBodyIsNotRetried(t *testing.T) {
+	var attempts int32
+	s := httptest.NewServer(failingHandler(http.StatusServiceUnavailable, 1, &attempts))
+	defer s.Close()
+
+	err := New(s.URL, WithRetryPolicy(testRetryPolicy())).Put("/").Body(strings.NewReader("content"), -1).Do(nil)
+	require.Error(t, err)
+	require.Equal(t, int32(1), attempts)
+}
+
+func TestDoStream(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte("{\"msg\":\"first\"}\n\n{\"msg\":\"second\"}\n{\"msg\":\"third\"}"))
+	}))
+	defer s.Close()
+
+	res, err := New(s.URL).Get("/").DoStream()
+	require.NoError(t, err)
+	defer res.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+
+	dec := res.Decoder()
+	var messages []string
+	for {
+		item := &testData{}
+		err := dec.Next(item)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		messages = append(messages, item.Message)
+	}
+	require.Equal(t, []string{"first", "second", "third"}, messages)
+}
+
+func TestDoStreamError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	_, err := New(s.URL).Get("/").DoStream()
+
+	var serverErr *ServerError
+	require.ErrorAs(t, err, &serverErr)
+	require.Equal(t, http.StatusNotFound, serverErr.StatusCode())
+}
+
+func TestStreamDecoderJSONSequence(t *testing.T) {
+	dec := NewStreamDecoder(strings.NewReader("\x1e{\"msg\":\"first\"}\n\x1e{\"msg\":\"second\"}\n"))
+
+	item := &testData{}
+	require.NoError(t, dec.Next(item))
+	require.Equal(t, "first", item.Message)
+	require.NoError(t, dec.Next(item))
+	require.Equal(t, "second", item.Message)
+	require.Equal(t, io.EOF, dec.Next(item))
+}
+
+func TestStreamDecoderInvalidItem(t *testing.T) {
+	dec := NewStreamDecoder(strings.NewReader("{invalid}\n"))
+	require.Error(t, dec.Next(&testData{}))
+}
@@ -2,6 +2,7 @@ package capo
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -17,22 +18,22 @@ var (
 	ErrEmptyBody = errors.New("the body is empty")
 )
 
-var m = marshaler.GetMarshaler()
-
 // Context is the request context.
 type Context struct {
 	ctx         context.Context
 	cancelled   bool
 	cancelCtxFn func()
-	w           http.ResponseWriter
+	w           *responseWriter
 	r           *http.Request
 
-	logger gnalog.Logger
+	logger   gnalog.Logger
+	registry *marshaler.Registry
 
 	err          error
 	status       int
 	responseData any
 	headers      map[string]string
+	onClose      []func(*Context)
 }
 
 // NewContext creates a new instance of context.
@@ -45,7 +46,7 @@ func NewContext(w http.ResponseWriter, r *http.Request) *Context {
 		cancelCtxFn: cancel,
 		cancelled:   false,
 		r:           r,
-		w:           w,
+		w:           &responseWriter{ResponseWriter: w},
 		headers:     make(map[string]string),
 	}
 }
@@ -60,6 +61,12 @@ func (ctx *Context) AddHeader(key string, value string) {
 	ctx.headers[key] = value
 }
 
+// Context returns the standard context of the request. It can be used to
+// propagate the cancellation and the context values to other operations.
+func (ctx *Context) Context() context.Context {
+	return ctx.ctx
+}
+
 // Deadline is the context deadline.
 func (ctx *Context) Deadline() (time.Time, bool) {
 	return ctx.ctx.Deadline()
@@ -86,8 +93,44 @@ func (ctx *Context) With(key any, value any) {
 	ctx.ctx = newCtx
 }
 
+// RequestMarshaler returns the marshaler for the request body according to the
+// "Content-Type" header. It returns the default marshaler if the header is not
+// set.
+func (ctx *Context) RequestMarshaler() (marshaler.Marshaler, error) {
+	marshalers := ctx.marshalers()
+
+	contentType := ctx.r.Header.Get("Content-Type")
+	if contentType == "" {
+		return marshalers.Default(), nil
+	}
+
+	m, ok := marshalers.Get(contentType)
+	if !ok {
+		err := fmt.Errorf("the content type %q is not supported", contentType)
+		return nil, NewServerError(UnsupportedMediaTypeErrorCode, err)
+	}
+
+	return m, nil
+}
+
+// ResponseMarshaler returns the marshaler for the response body according to
+// the "Accept" header. It returns the default marshaler if the header is not
+// set.
+func (ctx *Context) ResponseMarshaler() (marshaler.Marshaler, error) {
+	accept := ctx.r.Header.Get("Accept")
+
+	m, ok := ctx.marshalers().Negotiate(accept)
+	if !ok {
+		err := fmt.Errorf("the accepted media types %q are not supported", accept)
+		return nil, NewServerError(NotAcceptableErrorCode, err)
+	}
+
+	return m, nil
+}
+
 // Read takes the information in the request body and unmarshal the data in the
-// entity provided.
+// entity provided. It returns a bad request error if the body is empty or its
+// format is not valid.
 func (ctx *Context) Read(entity any) error {
 	data, err := io.ReadAll(ctx.r.Body)
 	if err != nil {
@@ -95,12 +138,17 @@ func (ctx *Context) Read(entity any) error {
 	}
 
 	if len(data) == 0 {
-		return ErrEmptyBody
+		return NewServerError(BadRequestErrorCode, ErrEmptyBody)
+	}
+
+	m, err := ctx.RequestMarshaler()
+	if err != nil {
+		return err
 	}
 
 	err = m.Unmarshal(data, entity)
 	if err != nil {
-		return fmt.Errorf("invalid body format :: %w", err)
+		return NewServerError(BadRequestErrorCode, fmt.Errorf("invalid body format :: %w", err))
 	}
 	return nil
 }
@@ -112,8 +160,29 @@ func (ctx *Context) Write(entity any) *Context {
 	return ctx
 }
 
-// Status returns the status code that the server will return to the client.
+// ResponseData returns the entity that will be written in the response.
+func (ctx *Context) ResponseData() any {
+	return ctx.responseData
+}
+
+// ResponseHeaders returns a copy of the headers that will be included in the
+// response.
+func (ctx *Context) ResponseHeaders() map[string]string {
+	headers := make(map[string]string, len(ctx.headers))
+	for key, value := range ctx.headers {
+		headers[key] = value
+	}
+
+	return headers
+}
+
+// Status returns the status code that the server will return to the client. It
+// is the status already sent if the response is committed.
 func (ctx *Context) Status() int {
+	if ctx.Committed() {
+		return ctx.w.status
+	}
+
 	if ctx.status <= 0 {
 		return http.StatusOK
 	}
@@ -141,7 +210,7 @@ func (ctx *Context) Cancel(err error) error {
 
 // Logger returns the logger for the current context.
 func (ctx *Context) Logger() gnalog.Logger {
-	if ctx.logger != nil {
+	if ctx.logger == nil {
 		// Create default logger if it does not exists.
 		ctx.SetLogger(gnalog.New())
 	}
@@ -154,24 +223,45 @@ func (ctx *Context) SetLogger(logger gnalog.Logger) {
 	ctx.logger = logger
 }
 
-func (ctx *Context) closeResponse() error {
-	// Set response status code.
-	if ctx.status > 0 {
-		ctx.w.WriteHeader(ctx.status)
+// marshalers returns the marshalers available for the request. If they are not
+// set by the server or group, it returns the marshalers registered in the
+// "marshaler" package.
+func (ctx *Context) marshalers() *marshaler.Registry {
+	if ctx.registry != nil {
+		return ctx.registry
 	}
 
-	// Add the headers.
-	for key, value := range ctx.headers {
-		ctx.w.Header().Add(key, value)
+	return marshaler.DefaultRegistry()
+}
+
+func (ctx *Context) closeResponse() error {
+	// The response was already sent by the handler.
+	if ctx.Committed() {
+		return nil
 	}
 
-	// Set the body data if it is needed.
+	// Marshal the body data if it is needed.
+	var data []byte
 	if ctx.responseData != nil {
-		data, err := m.Marshal(ctx.responseData)
+		var contentType string
+		var err error
+		data, contentType, err = ctx.marshalResponse()
 		if err != nil {
-			return fmt.Errorf("invalid entity format :: %w", err)
+			return err
 		}
-		_, err = ctx.w.Write(data)
+
+		// Keep the content type if it is set by the handlers.
+		if _, ok := ctx.headers["Content-Type"]; !ok && ctx.w.Header().Get("Content-Type") == "" {
+			ctx.w.Header().Set("Content-Type", contentType)
+		}
+	}
+
+	// Send the response status and headers.
+	ctx.commit()
+
+	// Set the body data if it is needed.
+	if data != nil {
+		_, err := ctx.w.Write(data)
 		if err != nil {
 			return fmt.Errorf("cannot write the response :: %w", err)
 		}
@@ -179,3 +269,34 @@ func (ctx *Context) closeResponse() error {
 
 	return nil
 }
+
+// marshalResponse encodes the response data with the marshaler accepted by the
+// client and returns it with its content type. The problem documents are always
+// encoded as JSON.
+func (ctx *Context) marshalResponse() ([]byte, string, error) {
+	if problem, ok := ctx.responseData.(*Problem); ok {
+		data, err := json.Marshal(problem)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid problem format :: %w", err)
+		}
+
+		return data, ProblemContentType, nil
+	}
+
+	m, err := ctx.ResponseMarshaler()
+	if err != nil {
+		// The client does not accept any response format. Send the error with
+		// the default one.
+		m = ctx.marshalers().Default()
+		res := responseError(ctx, err)
+		ctx.status = res.Status
+		ctx.responseData = res
+	}
+
+	data, err := m.Marshal(ctx.responseData)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid entity format :: %w", err)
+	}
+
+	return data, m.ContentTypeHeader(), nil
+}
@@ -0,0 +1,129 @@
+package capo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/client"
+)
+
+func TestContextImplementsContext(t *testing.T) {
+	var _ context.Context = &Context{}
+}
+
+func TestContextPropagatesRequestIDToClient(t *testing.T) {
+	downstream := New()
+	downstream.Get("/", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: ctx.Request().Header.Get(defaultReqIDHeaderKey)})
+		return nil
+	})
+
+	ds := httptest.NewServer(downstream)
+	defer ds.Close()
+
+	upstream := New()
+	upstream.UseBefore(CreateLog(""))
+	upstream.Get("/", func(ctx *Context) error {
+		res := &TestData{}
+		err := client.NewRequest().URL(ds.URL).DoContext(ctx, res)
+		if err != nil {
+			return err
+		}
+
+		ctx.Write(res)
+		return nil
+	})
+
+	us := httptest.NewServer(upstream)
+	defer us.Close()
+
+	res, err := http.Get(us.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data := &TestData{}
+	err = json.NewDecoder(res.Body).Decode(data)
+	require.NoError(t, err)
+	require.NotEmpty(t, data.Message)
+	require.Equal(t, res.Header.Get(defaultReqIDHeaderKey), data.Message)
+}
+
+func TestContextCancellationCancelsClientRequest(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer downstream.Close()
+
+	upstream := New()
+
+	clientErr := make(chan error, 1)
+	upstream.Get("/", func(ctx *Context) error {
+		err := client.NewRequest().URL(downstream.URL).DoContext(ctx, nil)
+		clientErr <- err
+		return err
+	})
+
+	us := httptest.NewServer(upstream)
+	defer us.Close()
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := client.NewRequest().URL(us.URL).DoContext(reqCtx, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case err := <-clientErr:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		require.Fail(t, "the downstream request was not cancelled")
+	}
+}
+
+func TestContextErrIsHandlerError(t *testing.T) {
+	serverHandler := New()
+
+	handlerErr := errors.New("handler error")
+	errs := make(chan error, 1)
+	serverHandler.UseAfterAlways(func(ctx *Context) {
+		errs <- ctx.Err()
+	})
+
+	cancelled := make(chan error, 2)
+	serverHandler.Get("/", func(ctx *Context) error {
+		// Wait until the client disconnects. The request context is cancelled,
+		// but there is no handler error yet.
+		<-ctx.Done()
+		cancelled <- ctx.Context().Err()
+		cancelled <- ctx.Err()
+
+		// The cancelled context is not used to perform more requests.
+		return client.NewRequest().URL("http://127.0.0.1:1").Retry(client.DefaultRetryPolicy()).DoContext(ctx, nil)
+	})
+	serverHandler.Get("/failure", func(ctx *Context) error {
+		return handlerErr
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := client.NewRequest().URL(s.URL).DoContext(reqCtx, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.ErrorIs(t, <-cancelled, context.Canceled)
+	require.NoError(t, <-cancelled)
+	require.ErrorIs(t, <-errs, context.Canceled)
+
+	err = client.NewRequest().URL(s.URL).RelativePath("failure").Do(nil)
+	require.NoError(t, err)
+	require.Equal(t, handlerErr, <-errs)
+}
@@ -1,18 +1,68 @@
 package capo
 
 import (
+	"errors"
 	"net/http"
 )
 
+// ErrorHandlingConfig is the configuration for the error handling middleware.
+type ErrorHandlingConfig struct {
+	// Debug includes the inner error message in the response. It must not be
+	// enabled in production environments.
+	Debug bool
+}
+
+// ErrorHandling writes the context error in the response with the default
+// configuration.
 func ErrorHandling(ctx *Context) {
-	ctxErr := ctx.Err()
-	if ctxErr != nil {
-		switch err := ctxErr.(type) {
-		case *ServerError:
-			ctx.Write(err)
-		default:
-			ctx.SetStatus(http.StatusInternalServerError)
-			ctx.Write(NewServerError(InternalServerErrorCode, err))
+	defaultErrorHandling(ctx)
+}
+
+var defaultErrorHandling = NewErrorHandling(ErrorHandlingConfig{})
+
+// NewErrorHandling returns the middleware that writes the context error in the
+// response. The errors that are not a "ServerError" are written as an internal
+// server error.
+func NewErrorHandling(config ErrorHandlingConfig) func(*Context) {
+	return func(ctx *Context) {
+		// The response cannot be changed if it is already sent.
+		ctxErr := ctx.Err()
+		if ctxErr == nil || ctx.Committed() {
+			return
 		}
+
+		res := responseError(ctx, ctxErr)
+		if config.Debug && res.inner != nil {
+			res.Debug = res.inner.Error()
+		}
+
+		ctx.SetStatus(res.Status)
+		ctx.Write(res)
+	}
+}
+
+// responseError returns a copy of the server error that will be sent to the
+// client with the status and message set.
+func responseError(ctx *Context, err error) *ServerError {
+	res := NewServerError(InternalServerErrorCode, err)
+
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) {
+		copied := *serverErr
+		res = &copied
 	}
+
+	res.Status = res.HTTPStatus()
+
+	// Keep the error status already set in the context if the error does not
+	// define it.
+	if _, ok := ErrorCodeStatus(res.Code); !ok && serverErr != nil && serverErr.Status == 0 && ctx.status >= http.StatusBadRequest {
+		res.Status = ctx.status
+	}
+
+	if res.Message == "" {
+		res.Message = http.StatusText(res.Status)
+	}
+
+	return res
 }
@@ -1,14 +1,26 @@
 package generic
 
-import "github.com/tonygcs/capo/client"
+import (
+	"context"
+
+	"github.com/tonygcs/capo/client"
+	"github.com/tonygcs/capo/marshaler"
+)
 
 // Request is a http request.
 type Request[T any, U any] struct {
 	r *client.Request
 }
 
-// NewRequest returns a new http request instance.
-func NewRequest[T any, U any]() *Request[T, U] {
+// NewRequest returns a new http request instance. If a client is provided, the
+// request is created with its configuration.
+func NewRequest[T any, U any](c ...*client.Client) *Request[T, U] {
+	if len(c) > 0 && c[0] != nil {
+		return &Request[T, U]{
+			r: c[0].NewRequest(),
+		}
+	}
+
 	return &Request[T, U]{
 		r: client.NewRequest(),
 	}
@@ -33,6 +45,26 @@ func (r *Request[T, U]) RelativePath(url string) *Request[T, U] {
 	return r
 }
 
+// Query adds a query parameter to the request url.
+func (r *Request[T, U]) Query(key string, value string) *Request[T, U] {
+	r.r.Query(key, value)
+	return r
+}
+
+// QueryStruct adds the fields of the struct provided tagged with "query" as
+// query parameters of the request url.
+func (r *Request[T, U]) QueryStruct(v interface{}) *Request[T, U] {
+	r.r.QueryStruct(v)
+	return r
+}
+
+// PathParam sets the value of a parameter in the url path template. e.g. the
+// "id" parameter in "/users/{id}".
+func (r *Request[T, U]) PathParam(name string, value interface{}) *Request[T, U] {
+	r.r.PathParam(name, value)
+	return r
+}
+
 // Method sets the request http method.
 func (r *Request[T, U]) Method(method string) *Request[T, U] {
 	r.r.Method(method)
@@ -45,9 +77,43 @@ func (r *Request[T, U]) Data(data *T) *Request[T, U] {
 	return r
 }
 
+// Marshaler sets the marshaler for the request and response bodies. If it is
+// not set, the request uses the default marshaler of the "marshaler" package.
+func (r *Request[T, U]) Marshaler(m marshaler.Marshaler) *Request[T, U] {
+	r.r.Marshaler(m)
+	return r
+}
+
+// Retry sets the retry policy for the request. It overrides the client retry
+// policy.
+func (r *Request[T, U]) Retry(policy *client.RetryPolicy) *Request[T, U] {
+	r.r.Retry(policy)
+	return r
+}
+
 // Do performs the http request.
 func (r *Request[T, U]) Do() (*U, error) {
+	return r.DoContext(context.Background())
+}
+
+// DoContext performs the http request with the context provided. The request
+// is cancelled if the context is done.
+func (r *Request[T, U]) DoContext(ctx context.Context) (*U, error) {
 	res := new(U)
-	err := r.r.Do(res)
+	err := r.r.DoContext(ctx, res)
 	return res, err
 }
+
+// DoWithResponse performs the http request and returns the response status,
+// headers and body.
+func (r *Request[T, U]) DoWithResponse() (*U, *client.Response, error) {
+	return r.DoContextWithResponse(context.Background())
+}
+
+// DoContextWithResponse performs the http request with the context provided
+// and returns the response status, headers and body.
+func (r *Request[T, U]) DoContextWithResponse(ctx context.Context) (*U, *client.Response, error) {
+	res := new(U)
+	response, err := r.r.DoContextWithResponse(ctx, res)
+	return res, response, err
+}
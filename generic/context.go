@@ -79,18 +79,27 @@ func (ctx *Context[T, U]) SetLogger(logger gnalog.Logger) {
 	ctx.ctx.SetLogger(logger)
 }
 
-// load takes the information in the request body and sets the 'Data' field in
-// the current context.
+// load takes the information in the request body and parameters, validates it
+// and sets the 'Data' field in the current context.
 func (ctx *Context[T, U]) load() error {
 	entity := new(T)
 
-	err := ctx.ctx.Read(entity)
-	if errors.Is(err, capo.ErrEmptyBody) {
-		entity = nil
-	} else if err != nil {
+	err := ctx.ctx.Bind(entity)
+	empty := errors.Is(err, capo.ErrEmptyBody)
+	if err != nil && !empty {
+		return err
+	}
+
+	// The entity is validated even if the body is empty, so the required fields
+	// are always enforced.
+	if err := capo.Validate(entity); err != nil {
 		return err
 	}
 
+	if empty {
+		entity = nil
+	}
+
 	ctx.Data = entity
 	return nil
 }
@@ -7,7 +7,9 @@ import (
 // Handler is the function definition for a HTTP handler.
 type Handler[T any, U any] func(ctx *Context[T, U]) error
 
-// WrapGenericHandler wraps a HTTP handler function to provide generics.
+// WrapGenericHandler wraps a HTTP handler function to provide generics. The
+// request data is validated with the "validate" struct tags before running the
+// handler.
 func WrapGenericHandler[T any, U any](handler Handler[T, U]) capo.Handler {
 	return func(ctx *capo.Context) error {
 		newCtx := NewContext[T, U](ctx)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/tonygcs/capo"
+	"github.com/tonygcs/capo/client"
 )
 
 type TestEntity struct {
@@ -45,3 +46,96 @@ func TestWrapGenericHandlerCanReadRequestBody(t *testing.T) {
 	_, err := NewRequest[TestEntity, any]().URL(s.URL).Method(http.MethodPost).Data(&TestEntity{Message: msg}).Do()
 	require.NoError(t, err)
 }
+
+type TestParamsEntity struct {
+	ID    int    `path:"id"`
+	Limit int    `query:"limit"`
+	Name  string `header:"X-Name"`
+}
+
+func TestWrapGenericHandlerCanBindRequestParams(t *testing.T) {
+	h := capo.New()
+
+	h.Get("/items/{id}", WrapGenericHandler(func(ctx *Context[TestParamsEntity, any]) error {
+		require.NotNil(t, ctx.Data)
+		require.Equal(t, 42, ctx.Data.ID)
+		require.Equal(t, 5, ctx.Data.Limit)
+		require.Equal(t, "test", ctx.Data.Name)
+		return nil
+	}))
+
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	err := client.NewRequest().URL(s.URL+"?limit=5").RelativePath("items/42").AddHeader("X-Name", "test").Do(nil)
+	require.NoError(t, err)
+}
+
+type TestValidEntity struct {
+	Message string `json:"msg" validate:"required,min=5"`
+}
+
+func TestWrapGenericHandlerValidatesRequestData(t *testing.T) {
+	h := capo.New()
+
+	// The middlewares must be registered before the routes.
+	h.UseAfterAlways(func(ctx *capo.Context) {
+		var serverErr *capo.ServerError
+		require.ErrorAs(t, ctx.Err(), &serverErr)
+		require.Equal(t, capo.ValidationErrorCode, serverErr.Code)
+	})
+	h.UseAfterAlways(capo.ErrorHandling)
+
+	calls := 0
+	h.Post("", WrapGenericHandler(func(ctx *Context[TestValidEntity, any]) error {
+		calls++
+		return nil
+	}))
+
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	tests := []struct {
+		name string
+		data *TestValidEntity
+	}{
+		{name: "invalid body", data: &TestValidEntity{Message: "abc"}},
+		{name: "empty body", data: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := client.NewRequest().URL(s.URL).Method(http.MethodPost)
+			if test.data != nil {
+				req.Data(test.data)
+			}
+
+			err := req.Do(nil)
+
+			var serverErr *client.ServerError
+			require.ErrorAs(t, err, &serverErr)
+			require.Equal(t, http.StatusUnprocessableEntity, serverErr.StatusCode())
+			require.Equal(t, capo.ValidationErrorCode, serverErr.Code())
+		})
+	}
+
+	require.Equal(t, 0, calls)
+}
+
+func TestNewRequestWithClient(t *testing.T) {
+	h := capo.New()
+
+	msg := "test client message"
+	h.Get("/api/items", WrapGenericHandler(func(ctx *Context[any, TestEntity]) error {
+		ctx.Write(&TestEntity{Message: msg})
+		return nil
+	}))
+
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	c := client.New(s.URL + "/api")
+	res, err := NewRequest[any, TestEntity](c).RelativePath("items").Do()
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+}
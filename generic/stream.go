@@ -0,0 +1,58 @@
+package generic
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/tonygcs/capo/client"
+)
+
+// Stream is an iterator over the items of a NDJSON or JSON sequence response.
+// It must be closed.
+type Stream[U any] struct {
+	res *client.StreamResponse
+	dec *client.StreamDecoder
+}
+
+// StatusCode returns the response status code.
+func (s *Stream[U]) StatusCode() int {
+	return s.res.StatusCode
+}
+
+// Header returns the response headers.
+func (s *Stream[U]) Header() http.Header {
+	return s.res.Header
+}
+
+// Next returns the next item of the stream. It returns "io.EOF" when there are
+// no more items.
+func (s *Stream[U]) Next() (*U, error) {
+	item := new(U)
+	if err := s.dec.Next(item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Close closes the response body.
+func (s *Stream[U]) Close() error {
+	return s.res.Close()
+}
+
+// Stream performs the http request and returns an iterator over the items of
+// the response.
+func (r *Request[T, U]) Stream() (*Stream[U], error) {
+	return r.StreamContext(context.Background())
+}
+
+// StreamContext performs the http request with the context provided and
+// returns an iterator over the items of the response.
+func (r *Request[T, U]) StreamContext(ctx context.Context) (*Stream[U], error) {
+	res, err := r.r.DoStreamContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stream[U]{res: res, dec: res.Decoder()}, nil
+}
@@ -0,0 +1,33 @@
+package generic
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestStream(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"msg\":\"first\"}\n{\"msg\":\"second\"}\n"))
+	}))
+	defer s.Close()
+
+	stream, err := NewRequest[any, TestEntity]().URL(s.URL).Stream()
+	require.NoError(t, err)
+	defer stream.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode())
+
+	item, err := stream.Next()
+	require.NoError(t, err)
+	require.Equal(t, "first", item.Message)
+
+	item, err = stream.Next()
+	require.NoError(t, err)
+	require.Equal(t, "second", item.Message)
+
+	_, err = stream.Next()
+	require.Equal(t, io.EOF, err)
+}
@@ -1,13 +1,13 @@
 package capo
 
 import (
-	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 	"sync"
 
 	"github.com/gorilla/mux"
+	"github.com/tonygcs/capo/marshaler"
 )
 
 type Group interface {
@@ -15,6 +15,7 @@ type Group interface {
 	getBeforeHandlers() []Handler
 	getAfterHandlers() []Handler
 	getAfterAlwaysHandlers() []func(*Context)
+	getMarshalers() *marshaler.Registry
 
 	// Use adds the handlers that will run before each request. Note that if a
 	// handler returns an error, the next handlers won't run.
@@ -25,6 +26,10 @@ type Group interface {
 	UseAfterAlways(handlers ...func(*Context))
 	// Group creates a new group to handle http requests.
 	Group(relativePath string) Group
+	// SetMarshalers sets the marshalers for the requests and responses of the
+	// group. The first marshaler is the default one. If they are not set, the
+	// group uses the parent marshalers.
+	SetMarshalers(marshalers ...marshaler.Marshaler)
 
 	// Get handles a GET request.
 	Get(relativePath string, handler Handler)
@@ -34,6 +39,21 @@ type Group interface {
 	Put(relativePath string, handler Handler)
 	// Get handles a DELETE request.
 	Delete(relativePath string, handler Handler)
+	// Patch handles a PATCH request.
+	Patch(relativePath string, handler Handler)
+	// Head handles a HEAD request. Note that HEAD requests are served
+	// automatically for every GET route.
+	Head(relativePath string, handler Handler)
+	// Options handles an OPTIONS request. Note that OPTIONS requests are served
+	// automatically for every route with the "Allow" header.
+	Options(relativePath string, handler Handler)
+	// Handle handles a request with the HTTP method provided.
+	Handle(method string, relativePath string, handler Handler)
+	// Any handles a request with any HTTP method.
+	Any(relativePath string, handler Handler)
+	// WebSocket handles the WebSocket connections in the path provided. The
+	// before middlewares run before the connection is upgraded.
+	WebSocket(relativePath string, handler WSHandler, opts ...WSOption)
 }
 
 // group is the group to wrap http handlers.
@@ -42,19 +62,22 @@ type group struct {
 
 	groupPath   string
 	router      *mux.Router
+	routes      *routeTable
 	parent      Group
 	children    []Group
 	before      []Handler
 	after       []Handler
 	afterAlways []func(*Context)
+	marshalers  *marshaler.Registry
 }
 
 // newGroup creates a new group instance.
-func newGroup(path string, parent Group, router *mux.Router) *group {
+func newGroup(path string, parent Group, router *mux.Router, routes *routeTable) *group {
 	return &group{
 		parent:      parent,
 		groupPath:   path,
 		router:      router,
+		routes:      routes,
 		children:    make([]Group, 0),
 		before:      make([]Handler, 0),
 		after:       make([]Handler, 0),
@@ -89,37 +112,114 @@ func (g *group) Group(relativePath string) Group {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	ng := newGroup(relativePath, g, g.router)
+	ng := newGroup(relativePath, g, g.router, g.routes)
 	g.children = append(g.children, ng)
 	return ng
 }
 
+// SetMarshalers sets the marshalers for the requests and responses of the
+// group. The first marshaler is the default one. If they are not set, the group
+// uses the parent marshalers.
+func (g *group) SetMarshalers(marshalers ...marshaler.Marshaler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// A registry without marshalers does not have a default one.
+	if len(marshalers) == 0 {
+		g.marshalers = nil
+		return
+	}
+
+	g.marshalers = marshaler.NewRegistry(marshalers...)
+}
+
 // Get handles a GET request.
 func (g *group) Get(relativePath string, handler Handler) {
-	path := joinPaths(g.path(), relativePath)
-	h := g.handlerToHttpHandler(handler)
-	g.router.HandleFunc(path, h).Methods(http.MethodGet)
+	g.Handle(http.MethodGet, relativePath, handler)
 }
 
 // Get handles a POST request.
 func (g *group) Post(relativePath string, handler Handler) {
-	path := joinPaths(g.path(), relativePath)
-	h := g.handlerToHttpHandler(handler)
-	g.router.HandleFunc(path, h).Methods(http.MethodPost)
+	g.Handle(http.MethodPost, relativePath, handler)
 }
 
 // Get handles a PUT request.
 func (g *group) Put(relativePath string, handler Handler) {
-	path := joinPaths(g.path(), relativePath)
-	h := g.handlerToHttpHandler(handler)
-	g.router.HandleFunc(path, h).Methods(http.MethodPut)
+	g.Handle(http.MethodPut, relativePath, handler)
 }
 
 // Get handles a DELETE request.
 func (g *group) Delete(relativePath string, handler Handler) {
+	g.Handle(http.MethodDelete, relativePath, handler)
+}
+
+// Patch handles a PATCH request.
+func (g *group) Patch(relativePath string, handler Handler) {
+	g.Handle(http.MethodPatch, relativePath, handler)
+}
+
+// Head handles a HEAD request. Note that HEAD requests are served
+// automatically for every GET route.
+func (g *group) Head(relativePath string, handler Handler) {
+	g.Handle(http.MethodHead, relativePath, handler)
+}
+
+// Options handles an OPTIONS request. Note that OPTIONS requests are served
+// automatically for every route with the "Allow" header.
+func (g *group) Options(relativePath string, handler Handler) {
+	g.Handle(http.MethodOptions, relativePath, handler)
+}
+
+// Handle handles a request with the HTTP method provided.
+func (g *group) Handle(method string, relativePath string, handler Handler) {
+	path := joinPaths(g.path(), relativePath)
+	h := g.handlerToHttpHandler(handler)
+	g.register(path, method)
+	g.router.HandleFunc(path, h).Methods(method)
+
+	// Serve the HEAD requests with the GET handler unless there is an explicit
+	// HEAD handler for the path.
+	if method == http.MethodGet {
+		g.router.HandleFunc(path, h).
+			Methods(http.MethodHead).
+			MatcherFunc(g.implicitMatcher(path, http.MethodHead))
+	}
+}
+
+// Any handles a request with any HTTP method.
+func (g *group) Any(relativePath string, handler Handler) {
 	path := joinPaths(g.path(), relativePath)
 	h := g.handlerToHttpHandler(handler)
-	g.router.HandleFunc(path, h).Methods(http.MethodDelete)
+	g.register(path, anyMethod)
+	g.router.HandleFunc(path, h)
+}
+
+// register includes the path method in the route table. The first time a path
+// is registered, it also adds the route that answers the OPTIONS requests
+// unless there is an explicit OPTIONS handler for the path.
+func (g *group) register(path string, method string) {
+	if !g.routes.add(path, method) {
+		return
+	}
+
+	h := g.handlerToHttpHandler(func(ctx *Context) error {
+		ctx.AddHeader("Allow", g.routes.allow(path))
+		ctx.SetStatus(http.StatusNoContent)
+		return nil
+	})
+
+	g.router.HandleFunc(path, h).
+		Methods(http.MethodOptions).
+		MatcherFunc(g.implicitMatcher(path, http.MethodOptions))
+}
+
+// implicitMatcher returns the route matcher for the requests served
+// automatically. It only matches if there is no explicit handler for the
+// method in the path.
+func (g *group) implicitMatcher(path string, method string) mux.MatcherFunc {
+	return func(r *http.Request, rm *mux.RouteMatch) bool {
+		return !g.routes.has(path, method)
+	}
 }
 
 func (g *group) path() string {
@@ -164,6 +264,18 @@ func (g *group) getAfterAlwaysHandlers() []func(*Context) {
 	return result
 }
 
+func (g *group) getMarshalers() *marshaler.Registry {
+	g.mu.Lock()
+	marshalers := g.marshalers
+	g.mu.Unlock()
+
+	if marshalers == nil && g.parent != nil {
+		return g.parent.getMarshalers()
+	}
+
+	return marshalers
+}
+
 func (g *group) handlerToHttpHandler(handler Handler) http.HandlerFunc {
 	g.mu.Lock()
 	defer g.mu.Unlock()
@@ -183,77 +295,28 @@ func (g *group) handlerToHttpHandler(handler Handler) http.HandlerFunc {
 	after = append(after, g.after...)
 	afterAlways = append(afterAlways, g.afterAlways...)
 
+	chain := &Chain{
+		Before:      before,
+		Handler:     handler,
+		After:       after,
+		AfterAlways: afterAlways,
+	}
+
 	// Return the HTTP handlers.
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := NewContext(w, r)
-
-		defer func() {
-			// Handle panics.
-			if rec := recover(); rec != nil {
-				var err error
-				switch r := rec.(type) {
-				case error:
-					err = r
-				default:
-					err = fmt.Errorf("%s", r)
-				}
-
-				ctx.Cancel(err)
-			}
-
-			// Run the middlewares that always will run after the request.
-			for _, h := range afterAlways {
-				h(ctx)
-			}
-
-			// Close the response. It writes all data on it.
-			err := ctx.closeResponse()
-
-			// Handle any error on closing the response.
-			if err != nil {
-				// Log the error.
-				ctx.Logger().With("error", err.Error()).Error("invalid response content")
-
-				// Set the 500 status code.
-				ctx.w.WriteHeader(http.StatusInternalServerError)
-
-				// Set the response body with the internal error.
-				internalErr := NewServerError(InternalServerErrorCode, err)
-				data, err := m.Marshal(internalErr)
-				if err != nil {
-					ctx.Logger().With("error", err.Error()).Error("the internal server error is invalid")
-				} else {
-					ctx.w.Write(data)
-				}
-			}
-		}()
-
-		var reqErr error
-
-		// Run before middlewares.
-		for _, h := range before {
-			reqErr = h(ctx)
-			if reqErr != nil {
-				ctx.Cancel(reqErr)
-				break
-			}
+		// The HEAD responses must not include the body.
+		if r.Method == http.MethodHead {
+			w = &headResponseWriter{ResponseWriter: w}
 		}
 
-		// Run the request handler.
-		if reqErr == nil {
-			reqErr = handler(ctx)
-		}
+		// Resolve the marshalers on every request, so they can be changed
+		// after the routes are registered. If there are no marshalers in the
+		// group hierarchy, the context uses the ones registered in the
+		// "marshaler" package.
+		ctx := NewContext(w, r)
+		ctx.registry = g.getMarshalers()
 
-		// Run after middlewares.
-		if reqErr == nil {
-			for _, h := range after {
-				reqErr = h(ctx)
-				if reqErr != nil {
-					ctx.Cancel(reqErr)
-					break
-				}
-			}
-		}
+		chain.Serve(ctx)
 	}
 }
 
@@ -262,6 +325,8 @@ func joinPaths(path1 string, path2 string) string {
 	if err != nil {
 		panic(err)
 	}
-	u.Path = path.Join(u.Path, path2)
-	return u.String()
+
+	// Return the raw path. The route templates (e.g. "/users/{id}") must not be
+	// escaped.
+	return path.Join(u.Path, path2)
 }
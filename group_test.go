@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/tonygcs/capo/client"
+	"github.com/tonygcs/capo/marshaler"
 )
 
 func TestGroupHandleGetRequest(t *testing.T) {
@@ -105,6 +106,53 @@ func TestGroupHandleDeleteRequest(t *testing.T) {
 	require.Equal(t, msg, res.Message)
 }
 
+func TestGroupHandlePatchRequest(t *testing.T) {
+	serverHandler := New()
+	group := serverHandler.Group("patch")
+
+	msg := "patch request test"
+	group.Patch("/request", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: msg})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	req := client.NewRequest().URL(s.URL).RelativePath("patch/request").Method(http.MethodPatch)
+	res := &TestData{}
+	err := req.Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+}
+
+func TestGroupHandleOptionsRequestRunsGroupMiddlewares(t *testing.T) {
+	serverHandler := New()
+	group := serverHandler.Group("options")
+	calls := 0
+	group.UseBefore(func(ctx *Context) error {
+		calls++
+		ctx.AddHeader("Access-Control-Allow-Origin", "*")
+		return nil
+	})
+
+	group.Delete("/request", func(ctx *Context) error { return nil })
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	req, err := http.NewRequest(http.MethodOptions, s.URL+"/options/request", nil)
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.Equal(t, "DELETE, OPTIONS", res.Header.Get("Allow"))
+	require.Equal(t, "*", res.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, 1, calls)
+}
+
 func TestRequestGroupAndEndpointWithoutPath(t *testing.T) {
 	serverHandler := New()
 	group := serverHandler.Group("")
@@ -341,3 +389,91 @@ func TestAfterErrorOnRootSopsGroupAfterMiddleware(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 2, calls)
 }
+
+func TestGroupOverridesServerMarshalers(t *testing.T) {
+	serverHandler := New()
+	serverHandler.SetMarshalers(&marshaler.XMLMarshaler{})
+
+	group := serverHandler.Group("json")
+	group.SetMarshalers(&marshaler.JSONMarshaler{})
+	child := group.Group("child")
+
+	msg := "marshaler request test"
+	handler := func(ctx *Context) error {
+		ctx.Write(&TestData{Message: msg})
+		return nil
+	}
+	serverHandler.Get("xml", handler)
+	child.Get("", handler)
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res := &TestData{}
+	err := client.NewRequest().URL(s.URL).RelativePath("json/child").Marshaler(&marshaler.JSONMarshaler{}).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+
+	res = &TestData{}
+	err = client.NewRequest().URL(s.URL).RelativePath("xml").Marshaler(&marshaler.XMLMarshaler{}).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+
+	// The JSON marshaler is not available in the server root.
+	err = client.NewRequest().URL(s.URL).RelativePath("xml").Do(nil)
+	require.Error(t, err)
+}
+
+func TestGroupWithoutMarshalersUsesParentMarshalers(t *testing.T) {
+	serverHandler := New()
+	serverHandler.SetMarshalers(&marshaler.XMLMarshaler{})
+
+	group := serverHandler.Group("group")
+	group.SetMarshalers()
+
+	msg := "empty marshalers test"
+	group.Get("", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: msg})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res := &TestData{}
+	err := client.NewRequest().URL(s.URL).RelativePath("group").Marshaler(&marshaler.XMLMarshaler{}).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+}
+
+func TestSetMarshalersAfterRouteRegistration(t *testing.T) {
+	serverHandler := New()
+
+	msg := "late marshalers test"
+	serverHandler.Get("server", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: msg})
+		return nil
+	})
+
+	group := serverHandler.Group("group")
+	group.Get("", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: msg})
+		return nil
+	})
+
+	serverHandler.SetMarshalers(&marshaler.XMLMarshaler{})
+	group.SetMarshalers(&marshaler.MsgPackMarshaler{})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res := &TestData{}
+	err := client.NewRequest().URL(s.URL).RelativePath("server").Marshaler(&marshaler.XMLMarshaler{}).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+
+	res = &TestData{}
+	err = client.NewRequest().URL(s.URL).RelativePath("group").Marshaler(&marshaler.MsgPackMarshaler{}).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+}
@@ -0,0 +1,14 @@
+// Package mediatype contains the media type helpers shared by the server and
+// the test packages.
+package mediatype
+
+import "strings"
+
+// IsText checks if the content type provided is a text format, e.g. JSON or
+// XML.
+func IsText(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "xml") ||
+		strings.Contains(contentType, "x-www-form-urlencoded")
+}
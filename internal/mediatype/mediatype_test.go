@@ -0,0 +1,28 @@
+package mediatype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsText(t *testing.T) {
+	tests := []struct {
+		contentType string
+		text        bool
+	}{
+		{contentType: "application/json", text: true},
+		{contentType: "application/problem+json", text: true},
+		{contentType: "application/xml", text: true},
+		{contentType: "text/plain", text: true},
+		{contentType: "application/x-www-form-urlencoded", text: true},
+		{contentType: "application/msgpack", text: false},
+		{contentType: "application/octet-stream", text: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.contentType, func(t *testing.T) {
+			require.Equal(t, test.text, IsText(test.contentType))
+		})
+	}
+}
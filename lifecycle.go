@@ -0,0 +1,303 @@
+package capo
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrServerRunning indicates the server is already running.
+	ErrServerRunning = errors.New("the server is already running")
+)
+
+const (
+	defaultShutdownTimeout      = 30 * time.Second
+	defaultShutdownHooksTimeout = 30 * time.Second
+	unixAddrPrefix              = "unix:"
+)
+
+// RunOption is an option to configure the HTTP server.
+type RunOption func(*runConfig)
+
+type runConfig struct {
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	shutdownTimeout   time.Duration
+	hooksTimeout      time.Duration
+	signals           []os.Signal
+	tlsConfig         *tls.Config
+}
+
+// WithReadTimeout sets the maximum duration for reading the entire request.
+func WithReadTimeout(timeout time.Duration) RunOption {
+	return func(c *runConfig) { c.readTimeout = timeout }
+}
+
+// WithReadHeaderTimeout sets the maximum duration for reading the request
+// headers.
+func WithReadHeaderTimeout(timeout time.Duration) RunOption {
+	return func(c *runConfig) { c.readHeaderTimeout = timeout }
+}
+
+// WithWriteTimeout sets the maximum duration before timing out writes of the
+// response.
+func WithWriteTimeout(timeout time.Duration) RunOption {
+	return func(c *runConfig) { c.writeTimeout = timeout }
+}
+
+// WithIdleTimeout sets the maximum amount of time to wait for the next request
+// when keep-alives are enabled.
+func WithIdleTimeout(timeout time.Duration) RunOption {
+	return func(c *runConfig) { c.idleTimeout = timeout }
+}
+
+// WithShutdownTimeout sets the maximum duration to drain the in-flight requests
+// when the server is stopped by a signal. The default value is 30 seconds.
+func WithShutdownTimeout(timeout time.Duration) RunOption {
+	return func(c *runConfig) { c.shutdownTimeout = timeout }
+}
+
+// WithShutdownHooksTimeout sets the maximum duration to run the "OnShutdown"
+// hooks. The hooks have their own time budget, so a slow drain of the in-flight
+// requests does not leave them with an expired context. The default value is 30
+// seconds.
+func WithShutdownHooksTimeout(timeout time.Duration) RunOption {
+	return func(c *runConfig) { c.hooksTimeout = timeout }
+}
+
+// WithSignals sets the signals that stop the server. The default signals are
+// SIGINT and SIGTERM.
+func WithSignals(signals ...os.Signal) RunOption {
+	return func(c *runConfig) { c.signals = signals }
+}
+
+// WithTLSConfig sets the TLS configuration for the server.
+func WithTLSConfig(config *tls.Config) RunOption {
+	return func(c *runConfig) { c.tlsConfig = config }
+}
+
+// runState is the state of a running server.
+type runState struct {
+	httpServer   *http.Server
+	hooksTimeout time.Duration
+	done         chan struct{}
+	running      bool
+	err          error
+}
+
+// OnStart adds the hooks that will run before the server starts accepting
+// requests. If a hook returns an error, the server won't start.
+func (s *Server) OnStart(hooks ...func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onStart = append(s.onStart, hooks...)
+}
+
+// OnShutdown adds the hooks that will run in order after the in-flight requests
+// are drained on the server shutdown. The hooks receive a context with their
+// own timeout (see "WithShutdownHooksTimeout").
+func (s *Server) OnShutdown(hooks ...func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onShutdown = append(s.onShutdown, hooks...)
+}
+
+// Run listens on the TCP network address provided and handles the requests. The
+// address can be a Unix socket path with the "unix:" prefix (e.g.
+// "unix:/tmp/capo.sock"). It blocks until the server is stopped by a signal or
+// the "Shutdown" method.
+func (s *Server) Run(addr string, opts ...RunOption) error {
+	return s.serve(listenAddr(addr), "", "", opts)
+}
+
+// RunTLS listens on the TCP network address provided and handles the HTTPS
+// requests. It blocks until the server is stopped by a signal or the "Shutdown"
+// method.
+func (s *Server) RunTLS(addr string, certFile string, keyFile string, opts ...RunOption) error {
+	return s.serve(listenAddr(addr), certFile, keyFile, opts)
+}
+
+// RunListener handles the requests from the listener provided. It blocks until
+// the server is stopped by a signal or the "Shutdown" method. The connections
+// are not accepted until the "OnStart" hooks finish.
+func (s *Server) RunListener(l net.Listener, opts ...RunOption) error {
+	used := false
+	err := s.serve(func() (net.Listener, error) { used = true; return l, nil }, "", "", opts)
+	if !used {
+		// The server did not start, close the listener anyway.
+		l.Close()
+	}
+
+	return err
+}
+
+// Shutdown stops the server gracefully. It waits for the in-flight requests
+// until the context is done and then runs the "OnShutdown" hooks with their own
+// timeout.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	state := s.state
+	if state == nil {
+		s.mu.Unlock()
+		return nil
+	}
+
+	// Wait for the shutdown in progress.
+	if !state.running {
+		s.mu.Unlock()
+		<-state.done
+		return state.err
+	}
+
+	state.running = false
+	hooks := append([]func(context.Context) error{}, s.onShutdown...)
+	s.mu.Unlock()
+
+	err := state.httpServer.Shutdown(ctx)
+	if err != nil {
+		err = fmt.Errorf("cannot drain the in-flight requests :: %w", err)
+	}
+
+	// The hooks do not share the context used to drain the requests, it can be
+	// already expired.
+	hooksCtx, cancel := context.WithTimeout(context.Background(), state.hooksTimeout)
+	defer cancel()
+
+	// Run all the hooks even if one of them fails.
+	for _, hook := range hooks {
+		hookErr := hook(hooksCtx)
+		if hookErr != nil && err == nil {
+			err = fmt.Errorf("shutdown hook failed :: %w", hookErr)
+		}
+	}
+
+	s.mu.Lock()
+	state.err = err
+	s.state = nil
+	s.mu.Unlock()
+
+	close(state.done)
+	return err
+}
+
+func (s *Server) serve(listen func() (net.Listener, error), certFile string, keyFile string, opts []RunOption) error {
+	config := &runConfig{
+		shutdownTimeout: defaultShutdownTimeout,
+		hooksTimeout:    defaultShutdownHooksTimeout,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	state := &runState{
+		httpServer: &http.Server{
+			Handler:           s,
+			ReadTimeout:       config.readTimeout,
+			ReadHeaderTimeout: config.readHeaderTimeout,
+			WriteTimeout:      config.writeTimeout,
+			IdleTimeout:       config.idleTimeout,
+			TLSConfig:         config.tlsConfig,
+		},
+		hooksTimeout: config.hooksTimeout,
+		done:         make(chan struct{}),
+		running:      true,
+	}
+
+	s.mu.Lock()
+	if s.state != nil {
+		s.mu.Unlock()
+		return ErrServerRunning
+	}
+	s.state = state
+	hooks := append([]func() error{}, s.onStart...)
+	s.mu.Unlock()
+
+	// Run the start hooks before listening, so the server does not receive
+	// connections until they finish.
+	for _, hook := range hooks {
+		err := hook()
+		if err != nil {
+			s.release()
+			return fmt.Errorf("start hook failed :: %w", err)
+		}
+	}
+
+	l, err := listen()
+	if err != nil {
+		s.release()
+		return err
+	}
+
+	// Listen for the stop signals.
+	signals := make(chan os.Signal, 1)
+	if len(config.signals) > 0 {
+		signal.Notify(signals, config.signals...)
+		defer signal.Stop(signals)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if certFile != "" || keyFile != "" || config.tlsConfig != nil {
+			serveErr <- state.httpServer.ServeTLS(l, certFile, keyFile)
+		} else {
+			serveErr <- state.httpServer.Serve(l)
+		}
+	}()
+
+	select {
+	case <-signals:
+		ctx, cancel := context.WithTimeout(context.Background(), config.shutdownTimeout)
+		defer cancel()
+		return s.Shutdown(ctx)
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			// The server was stopped by the "Shutdown" method.
+			<-state.done
+			return state.err
+		}
+
+		// Release the resources if the server cannot serve.
+		ctx, cancel := context.WithTimeout(context.Background(), config.shutdownTimeout)
+		defer cancel()
+		s.Shutdown(ctx)
+		return fmt.Errorf("cannot serve the requests :: %w", err)
+	}
+}
+
+// release removes the state of a server that cannot run.
+func (s *Server) release() {
+	s.mu.Lock()
+	s.state = nil
+	s.mu.Unlock()
+}
+
+// listenAddr returns the function that listens on the address provided.
+func listenAddr(addr string) func() (net.Listener, error) {
+	return func() (net.Listener, error) {
+		network := "tcp"
+		if strings.HasPrefix(addr, unixAddrPrefix) {
+			network = "unix"
+			addr = strings.TrimPrefix(addr, unixAddrPrefix)
+		}
+
+		l, err := net.Listen(network, addr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot listen on %s :: %w", addr, err)
+		}
+
+		return l, nil
+	}
+}
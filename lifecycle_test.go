@@ -0,0 +1,195 @@
+package capo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/client"
+)
+
+func TestServerRunsAndShutdownsWithHooks(t *testing.T) {
+	serverHandler := New()
+
+	calls := []string{}
+	serverHandler.OnStart(func() error { calls = append(calls, "start"); return nil })
+	serverHandler.OnShutdown(
+		func(ctx context.Context) error { calls = append(calls, "shutdown-1"); return nil },
+		func(ctx context.Context) error { calls = append(calls, "shutdown-2"); return nil },
+	)
+
+	msg := "hello run test"
+	serverHandler.Get("/", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: msg})
+		return nil
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- serverHandler.RunListener(l, WithSignals()) }()
+
+	res := &TestData{}
+	err = client.NewRequest().URL("http://" + l.Addr().String()).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+
+	err = serverHandler.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, <-runErr)
+	require.Equal(t, []string{"start", "shutdown-1", "shutdown-2"}, calls)
+}
+
+func TestServerShutdownDrainsInFlightRequests(t *testing.T) {
+	serverHandler := New()
+
+	started := make(chan struct{})
+	serverHandler.Get("/", func(ctx *Context) error {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		ctx.Write(&TestData{Message: "drained"})
+		return nil
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- serverHandler.RunListener(l, WithSignals()) }()
+
+	reqErr := make(chan error, 1)
+	res := &TestData{}
+	go func() { reqErr <- client.NewRequest().URL("http://" + l.Addr().String()).Do(res) }()
+
+	<-started
+	err = serverHandler.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, <-runErr)
+	require.NoError(t, <-reqErr)
+	require.Equal(t, "drained", res.Message)
+}
+
+func TestServerDoesNotStartIfStartHookFails(t *testing.T) {
+	serverHandler := New()
+
+	hookErr := errors.New("test hook error")
+	serverHandler.OnStart(func() error { return hookErr })
+
+	err := serverHandler.Run("127.0.0.1:0", WithSignals())
+	require.ErrorIs(t, err, hookErr)
+}
+
+func TestServerRunsOnUnixSocket(t *testing.T) {
+	serverHandler := New()
+
+	msg := "hello unix test"
+	serverHandler.Get("/", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: msg})
+		return nil
+	})
+
+	socket := filepath.Join(t.TempDir(), "capo.sock")
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- serverHandler.Run("unix:"+socket, WithSignals()) }()
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	require.Eventually(t, func() bool {
+		res, err := httpClient.Get("http://unix/")
+		if err != nil {
+			return false
+		}
+		res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	err := serverHandler.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, <-runErr)
+}
+
+func TestServerRunsStartHooksBeforeListening(t *testing.T) {
+	serverHandler := New()
+
+	// Take a free address.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	hookErr := make(chan error, 1)
+	serverHandler.OnStart(func() error {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		hookErr <- err
+		return nil
+	})
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- serverHandler.Run(addr, WithSignals()) }()
+
+	require.Error(t, <-hookErr, "the server accepts connections before the start hooks finish")
+
+	require.Eventually(t, func() bool {
+		res, err := http.Get("http://" + addr)
+		if err != nil {
+			return false
+		}
+		res.Body.Close()
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	err = serverHandler.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, <-runErr)
+}
+
+func TestServerShutdownHooksHaveTheirOwnTimeout(t *testing.T) {
+	serverHandler := New()
+
+	hookErr := make(chan error, 1)
+	serverHandler.OnShutdown(func(ctx context.Context) error {
+		hookErr <- ctx.Err()
+		return nil
+	})
+
+	started := make(chan struct{})
+	serverHandler.Get("/", func(ctx *Context) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- serverHandler.RunListener(l, WithSignals(), WithShutdownHooksTimeout(time.Second)) }()
+
+	go client.NewRequest().URL("http://" + l.Addr().String()).Do(nil)
+	<-started
+
+	// The drain of the in-flight request is slower than the shutdown context.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = serverHandler.Shutdown(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, <-hookErr)
+	require.ErrorIs(t, <-runErr, context.DeadlineExceeded)
+}
@@ -13,6 +13,7 @@ type contextKey string
 const (
 	defaultReqIDHeaderKey string     = "X-Request-ID"
 	incomingTimeKey       contextKey = "INCOMING_TIME_KEY"
+	requestIDKey          contextKey = "REQUEST_ID_KEY"
 )
 
 // CreateLog returns the middleware to create a log entity in the request
@@ -33,26 +34,39 @@ func CreateLog(requestIDHeaderKey string) Handler {
 
 		ctx.SetLogger(l)
 		ctx.With(incomingTimeKey, time.Now())
+		ctx.With(requestIDKey, requestID)
 
 		return nil
 	}
 }
 
-// LogRequest logs the request that is about to finish. It logs (INFO level) the
-// request result status, method, endpoint, error (if it exists) and run time.
-// In case the result status is an internal error, the middleware will log the
-// record as an ERROR.
+// RequestID returns the request id set by the "CreateLog" middleware. It
+// returns an empty string if it does not exist.
+func (ctx *Context) RequestID() string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// LogRequest logs the request once the response is written. It logs (INFO
+// level) the request result status, method, endpoint, response bytes written,
+// error (if it exists) and run time. In case the result status is an internal
+// error, the middleware will log the record as an ERROR.
 func LogRequest(ctx *Context) {
+	ctx.OnClose(logRequest)
+}
+
+func logRequest(ctx *Context) {
 	status := ctx.Status()
 
 	l := ctx.Logger().
 		With("method", ctx.r.Method).
 		With("endpoint", ctx.r.URL.Path).
-		With("status", status)
+		With("status", status).
+		With("bytes", ctx.BytesWritten())
 
 	// Log the error message.
 	if err := ctx.Err(); err != nil {
-		l.With("error", err.Error())
+		l = l.With("error", err.Error())
 	}
 
 	// Calculate time.
@@ -1,9 +1,12 @@
 package capo
 
 import (
+	"context"
 	"net/http"
+	"sync"
 
 	"github.com/gorilla/mux"
+	"github.com/tonygcs/capo/marshaler"
 )
 
 // Handler is the data type for the method that will handle the HTTP requests.
@@ -14,13 +17,18 @@ type Handler func(*Context) error
 type Server struct {
 	g Group
 	r *mux.Router
+
+	mu         sync.Mutex
+	state      *runState
+	onStart    []func() error
+	onShutdown []func(context.Context) error
 }
 
 // New creates a new server instance.
 func New() *Server {
 	router := mux.NewRouter()
 	return &Server{
-		g: newGroup("/", nil, router),
+		g: newGroup("/", nil, router, newRouteTable()),
 		r: router,
 	}
 }
@@ -60,6 +68,13 @@ func (s *Server) Group(relativePath string) Group {
 	return s.g.Group(relativePath)
 }
 
+// SetMarshalers sets the marshalers for the requests and responses of the
+// server. The first marshaler is the default one. If they are not set, the
+// server uses the marshalers registered in the "marshaler" package.
+func (s *Server) SetMarshalers(marshalers ...marshaler.Marshaler) {
+	s.g.SetMarshalers(marshalers...)
+}
+
 // Get handles a GET request.
 func (s *Server) Get(relativePath string, handler Handler) {
 	s.g.Get(relativePath, handler)
@@ -80,6 +95,39 @@ func (s *Server) Delete(relativePath string, handler Handler) {
 	s.g.Delete(relativePath, handler)
 }
 
+// Patch handles a PATCH request.
+func (s *Server) Patch(relativePath string, handler Handler) {
+	s.g.Patch(relativePath, handler)
+}
+
+// Head handles a HEAD request. Note that HEAD requests are served
+// automatically for every GET route.
+func (s *Server) Head(relativePath string, handler Handler) {
+	s.g.Head(relativePath, handler)
+}
+
+// Options handles an OPTIONS request. Note that OPTIONS requests are served
+// automatically for every route with the "Allow" header.
+func (s *Server) Options(relativePath string, handler Handler) {
+	s.g.Options(relativePath, handler)
+}
+
+// Handle handles a request with the HTTP method provided.
+func (s *Server) Handle(method string, relativePath string, handler Handler) {
+	s.g.Handle(method, relativePath, handler)
+}
+
+// Any handles a request with any HTTP method.
+func (s *Server) Any(relativePath string, handler Handler) {
+	s.g.Any(relativePath, handler)
+}
+
+// WebSocket handles the WebSocket connections in the path provided. The before
+// middlewares run before the connection is upgraded.
+func (s *Server) WebSocket(relativePath string, handler WSHandler, opts ...WSOption) {
+	s.g.WebSocket(relativePath, handler, opts...)
+}
+
 func (s *Server) path() string {
 	return s.g.path()
 }
@@ -95,3 +143,7 @@ func (s *Server) getAfterHandlers() []Handler {
 func (s *Server) getAfterAlwaysHandlers() []func(*Context) {
 	return s.g.getAfterAlwaysHandlers()
 }
+
+func (s *Server) getMarshalers() *marshaler.Registry {
+	return s.g.getMarshalers()
+}
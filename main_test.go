@@ -1,13 +1,16 @@
 package capo
 
 import (
+	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/tonygcs/capo/client"
+	"github.com/tonygcs/capo/marshaler"
 )
 
 type TestData struct {
@@ -108,6 +111,153 @@ func TestServerHandleDeleteRequest(t *testing.T) {
 	require.Equal(t, msg, res.Message)
 }
 
+func TestServerHandlePatchRequest(t *testing.T) {
+	serverHandler := New()
+
+	reqMsg := "hello request test"
+	resMsg := "hello response test"
+	serverHandler.Patch("/patch", func(ctx *Context) error {
+		// Check request data.
+		req := &TestData{}
+		err := ctx.Read(req)
+		require.NoError(t, err)
+		require.Equal(t, reqMsg, req.Message)
+
+		ctx.Write(&TestData{Message: resMsg})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	req := client.NewRequest().URL(s.URL).RelativePath("patch").Method(http.MethodPatch).Data(&TestData{Message: reqMsg})
+	res := &TestData{}
+	err := req.Do(res)
+	require.NoError(t, err)
+	require.Equal(t, resMsg, res.Message)
+}
+
+func TestServerHandleCustomMethodRequest(t *testing.T) {
+	serverHandler := New()
+
+	msg := "hello custom method test"
+	serverHandler.Handle("PURGE", "/cache", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: msg})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	req := client.NewRequest().URL(s.URL).RelativePath("cache").Method("PURGE")
+	res := &TestData{}
+	err := req.Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+}
+
+func TestServerHandleAnyMethodRequest(t *testing.T) {
+	serverHandler := New()
+
+	calls := 0
+	serverHandler.Any("/any", func(ctx *Context) error { calls++; return nil })
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+		err := client.NewRequest().URL(s.URL).RelativePath("any").Method(method).Do(nil)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 4, calls)
+}
+
+func TestServerHandleHeadRequestWithGetHandler(t *testing.T) {
+	serverHandler := New()
+
+	calls := 0
+	serverHandler.UseBefore(func(ctx *Context) error { calls++; return nil })
+	serverHandler.Get("/", func(ctx *Context) error {
+		ctx.AddHeader("X-Test", "test")
+		ctx.Write(&TestData{Message: "hello head test"})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Head(s.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "test", res.Header.Get("X-Test"))
+	require.Empty(t, body)
+	require.Equal(t, 1, calls)
+}
+
+func TestServerExplicitHeadHandlerOverridesGetHandler(t *testing.T) {
+	serverHandler := New()
+
+	serverHandler.Get("/", func(ctx *Context) error { ctx.SetStatus(http.StatusOK); return nil })
+	serverHandler.Head("/", func(ctx *Context) error { ctx.SetStatus(http.StatusAccepted); return nil })
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Head(s.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+}
+
+func TestServerHandleOptionsRequestWithAllowHeader(t *testing.T) {
+	serverHandler := New()
+
+	calls := 0
+	serverHandler.UseBefore(func(ctx *Context) error { calls++; return nil })
+	serverHandler.Get("/resource", func(ctx *Context) error { return nil })
+	serverHandler.Post("/resource", func(ctx *Context) error { return nil })
+	serverHandler.Patch("/resource", func(ctx *Context) error { return nil })
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	req, err := http.NewRequest(http.MethodOptions, s.URL+"/resource", nil)
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.Equal(t, "GET, HEAD, OPTIONS, PATCH, POST", res.Header.Get("Allow"))
+	require.Equal(t, 1, calls)
+}
+
+func TestServerExplicitOptionsHandlerOverridesAllowHeader(t *testing.T) {
+	serverHandler := New()
+
+	serverHandler.Get("/resource", func(ctx *Context) error { return nil })
+	serverHandler.Options("/resource", func(ctx *Context) error {
+		ctx.AddHeader("Allow", "GET")
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	req, err := http.NewRequest(http.MethodOptions, s.URL+"/resource", nil)
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "GET", res.Header.Get("Allow"))
+}
+
 func TestRequestRunsAllMiddlewareTypes(t *testing.T) {
 	serverHandler := New()
 	calls := 0
@@ -158,6 +308,27 @@ func TestHandlerErrorRunsAfterAlwaysMiddlewares(t *testing.T) {
 	require.Equal(t, 3, calls)
 }
 
+func TestHandlerErrorCancelsRequest(t *testing.T) {
+	serverHandler := New()
+
+	handlerErr := errors.New("test error")
+	errs := make(chan error, 2)
+	serverHandler.UseAfterAlways(func(ctx *Context) {
+		errs <- ctx.Err()
+		errs <- ctx.Context().Err()
+	})
+
+	serverHandler.Get("", func(ctx *Context) error { return handlerErr })
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	err := client.NewRequest().URL(s.URL).Do(nil)
+	require.NoError(t, err)
+	require.Equal(t, handlerErr, <-errs)
+	require.ErrorIs(t, <-errs, context.Canceled)
+}
+
 func TestBeforeMiddlewareStopsPropagation(t *testing.T) {
 	serverHandler := New()
 	calls := 0
@@ -205,3 +376,55 @@ func TestAfterAlwaysIsCalledIfRequestPanics(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, calls)
 }
+
+func TestServersWithDifferentMarshalers(t *testing.T) {
+	jsonHandler := New()
+	xmlHandler := New()
+	xmlHandler.SetMarshalers(&marshaler.XMLMarshaler{})
+
+	msg := "hello marshaler test"
+	for _, h := range []*Server{jsonHandler, xmlHandler} {
+		h.Get("/", func(ctx *Context) error {
+			ctx.Write(&TestData{Message: msg})
+			return nil
+		})
+	}
+
+	jsonServer := httptest.NewServer(jsonHandler)
+	defer jsonServer.Close()
+	xmlServer := httptest.NewServer(xmlHandler)
+	defer xmlServer.Close()
+
+	res := &TestData{}
+	err := client.NewRequest().URL(jsonServer.URL).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+
+	res = &TestData{}
+	err = client.NewRequest().URL(xmlServer.URL).Marshaler(&marshaler.XMLMarshaler{}).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+}
+
+func TestServerUsesDefaultMarshalerSetAtRuntime(t *testing.T) {
+	serverHandler := New()
+
+	msg := "hello default marshaler test"
+	serverHandler.Get("/", func(ctx *Context) error {
+		require.Equal(t, "application/xml", ctx.Request().Header.Get("Accept"))
+		ctx.Write(&TestData{Message: msg})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	defaultMarshaler := marshaler.GetMarshaler()
+	marshaler.SetMarshaler(&marshaler.XMLMarshaler{})
+	defer marshaler.SetMarshaler(defaultMarshaler)
+
+	res := &TestData{}
+	err := client.NewRequest().URL(s.URL).Do(res)
+	require.NoError(t, err)
+	require.Equal(t, msg, res.Message)
+}
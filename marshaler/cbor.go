@@ -0,0 +1,20 @@
+package marshaler
+
+import "github.com/fxamacker/cbor/v2"
+
+// CBORMarshaler transforms the entities in CBOR format. The struct fields are
+// named with the "cbor" or "json" tags.
+type CBORMarshaler struct {
+}
+
+func (m *CBORMarshaler) ContentTypeHeader() string {
+	return "application/cbor"
+}
+
+func (m *CBORMarshaler) Unmarshal(data []byte, entity interface{}) error {
+	return cbor.Unmarshal(data, entity)
+}
+
+func (m *CBORMarshaler) Marshal(entity interface{}) ([]byte, error) {
+	return cbor.Marshal(entity)
+}
@@ -0,0 +1,280 @@
+package marshaler
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// FormMarshaler transforms the entities in "application/x-www-form-urlencoded"
+// format. The entities can be "url.Values", string maps or structs. The struct
+// fields are named with the "form" tags, e.g.
+//
+//	type Input struct {
+//		Name string   `form:"name"`
+//		Tags []string `form:"tag"`
+//	}
+//
+// The interface fields (e.g. the "any" fields) are skipped, since the form
+// values do not have a type to decode them.
+type FormMarshaler struct {
+}
+
+func (m *FormMarshaler) ContentTypeHeader() string {
+	return "application/x-www-form-urlencoded"
+}
+
+func (m *FormMarshaler) Unmarshal(data []byte, entity interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	switch e := entity.(type) {
+	case *url.Values:
+		*e = values
+		return nil
+	case *map[string][]string:
+		*e = values
+		return nil
+	case *map[string]string:
+		*e = make(map[string]string, len(values))
+		for key := range values {
+			(*e)[key] = values.Get(key)
+		}
+		return nil
+	}
+
+	v := reflect.ValueOf(entity)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal form into %T", entity)
+	}
+
+	return unmarshalForm(values, v.Elem())
+}
+
+func (m *FormMarshaler) Marshal(entity interface{}) ([]byte, error) {
+	switch e := entity.(type) {
+	case url.Values:
+		return []byte(e.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(e).Encode()), nil
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range e {
+			values.Set(key, value)
+		}
+		return []byte(values.Encode()), nil
+	}
+
+	v := reflect.ValueOf(entity)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot marshal %T as form", entity)
+	}
+
+	values := url.Values{}
+	err := marshalForm(values, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(values.Encode()), nil
+}
+
+// formFieldName returns the form name of the struct field. It returns false if
+// the field must be skipped.
+func formFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Type.Kind() == reflect.Interface {
+		return "", false
+	}
+
+	name, ok := field.Tag.Lookup("form")
+	if name == "-" {
+		return "", false
+	}
+
+	if !ok || name == "" {
+		return field.Name, true
+	}
+
+	return name, true
+}
+
+func marshalForm(values url.Values, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			err := marshalForm(values, fieldValue)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, ok := formFieldName(field)
+		if !ok {
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Slice && !field.Type.Implements(textMarshalerType) {
+			for j := 0; j < fieldValue.Len(); j++ {
+				value, err := formatFormValue(fieldValue.Index(j))
+				if err != nil {
+					return fmt.Errorf("invalid field %s :: %w", name, err)
+				}
+				values.Add(name, value)
+			}
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() {
+			continue
+		}
+
+		value, err := formatFormValue(fieldValue)
+		if err != nil {
+			return fmt.Errorf("invalid field %s :: %w", name, err)
+		}
+		values.Set(name, value)
+	}
+
+	return nil
+}
+
+func formatFormValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func unmarshalForm(values url.Values, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			err := unmarshalForm(values, fieldValue)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, ok := formFieldName(field)
+		if !ok {
+			continue
+		}
+
+		fieldValues, ok := values[name]
+		if !ok || len(fieldValues) == 0 {
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Slice && !reflect.PointerTo(field.Type).Implements(textUnmarshalerType) {
+			result := reflect.MakeSlice(field.Type, len(fieldValues), len(fieldValues))
+			for j, value := range fieldValues {
+				err := parseFormValue(result.Index(j), value)
+				if err != nil {
+					return fmt.Errorf("invalid field %s :: %w", name, err)
+				}
+			}
+			fieldValue.Set(result)
+			continue
+		}
+
+		err := parseFormValue(fieldValue, fieldValues[0])
+		if err != nil {
+			return fmt.Errorf("invalid field %s :: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func parseFormValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		err := parseFormValue(elem.Elem(), value)
+		if err != nil {
+			return err
+		}
+
+		v.Set(elem)
+		return nil
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
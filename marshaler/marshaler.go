@@ -1,16 +1,27 @@
 package marshaler
 
-var mars Marshaler = &JSONMarshaler{}
+var registry = NewRegistry(&JSONMarshaler{})
 
-// GetMarshaler returns the current marshaler instance.
+// GetMarshaler returns the current default marshaler instance.
 func GetMarshaler() Marshaler {
-	return mars
+	return registry.Default()
 }
 
-// SetMarshaler sets the entity that will transform the requests and responses
-// in the entities that the application can handle.
+// SetMarshaler sets the default entity that will transform the requests and
+// responses in the entities that the application can handle.
 func SetMarshaler(m Marshaler) {
-	mars = m
+	registry.SetDefault(m)
+}
+
+// Register adds a marshaler to the default registry. The server will use it for
+// the requests and responses with its media type.
+func Register(m Marshaler) {
+	registry.Register(m)
+}
+
+// DefaultRegistry returns the registry with all the marshalers registered.
+func DefaultRegistry() *Registry {
+	return registry
 }
 
 // Marshaler is the entity that will transform the HTTP requests and responses.
@@ -0,0 +1,114 @@
+package marshaler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo"
+	"github.com/tonygcs/capo/client"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+type testEntity struct {
+	Message string   `json:"msg" xml:"msg" form:"msg"`
+	Count   int      `json:"count" xml:"count" form:"count"`
+	Enabled *bool    `json:"enabled" xml:"enabled" form:"enabled"`
+	Tags    []string `json:"tags" xml:"tag" form:"tag"`
+}
+
+func TestXMLMarshalerRoundTrip(t *testing.T) {
+	testMarshalerRoundTrip(t, &marshaler.XMLMarshaler{})
+}
+
+func TestFormMarshalerRoundTrip(t *testing.T) {
+	testMarshalerRoundTrip(t, &marshaler.FormMarshaler{})
+}
+
+func TestMsgPackMarshalerRoundTrip(t *testing.T) {
+	testMarshalerRoundTrip(t, &marshaler.MsgPackMarshaler{})
+}
+
+func TestCBORMarshalerRoundTrip(t *testing.T) {
+	testMarshalerRoundTrip(t, &marshaler.CBORMarshaler{})
+}
+
+func testMarshalerRoundTrip(t *testing.T, m marshaler.Marshaler) {
+	h := capo.New()
+	h.SetMarshalers(&marshaler.JSONMarshaler{}, m)
+	h.Post("/echo", func(ctx *capo.Context) error {
+		contentType := ctx.Request().Header.Get("Content-Type")
+		require.Equal(t, m.ContentTypeHeader(), contentType)
+
+		entity := &testEntity{}
+		err := ctx.Read(entity)
+		require.NoError(t, err)
+
+		entity.Count++
+		ctx.Write(entity)
+		return nil
+	})
+
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	enabled := true
+	req := &testEntity{Message: "round trip", Count: 41, Enabled: &enabled, Tags: []string{"a", "b"}}
+	res := &testEntity{}
+	err := client.NewRequest().
+		URL(s.URL).
+		RelativePath("echo").
+		Method(http.MethodPost).
+		Marshaler(m).
+		Data(req).
+		Do(res)
+	require.NoError(t, err)
+
+	require.Equal(t, req.Message, res.Message)
+	require.Equal(t, 42, res.Count)
+	require.NotNil(t, res.Enabled)
+	require.True(t, *res.Enabled)
+	require.Equal(t, req.Tags, res.Tags)
+}
+
+func TestFormMarshalerSupportsValuesAndMaps(t *testing.T) {
+	m := &marshaler.FormMarshaler{}
+
+	data, err := m.Marshal(url.Values{"a": {"1", "2"}, "b": {"3"}})
+	require.NoError(t, err)
+	require.Equal(t, "a=1&a=2&b=3", string(data))
+
+	data, err = m.Marshal(map[string]string{"key": "value with spaces"})
+	require.NoError(t, err)
+	require.Equal(t, "key=value+with+spaces", string(data))
+
+	values := url.Values{}
+	err = m.Unmarshal([]byte("a=1&a=2&b=3"), &values)
+	require.NoError(t, err)
+	require.Equal(t, url.Values{"a": {"1", "2"}, "b": {"3"}}, values)
+
+	flat := map[string]string{}
+	err = m.Unmarshal([]byte("a=1&a=2&b=3"), &flat)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "1", "b": "3"}, flat)
+}
+
+func TestFormMarshalerSkipsInterfaceFields(t *testing.T) {
+	type testDetails struct {
+		Code    string `form:"code"`
+		Details any    `form:"details"`
+	}
+
+	m := &marshaler.FormMarshaler{}
+
+	data, err := m.Marshal(&testDetails{Code: "ERR", Details: []string{"a"}})
+	require.NoError(t, err)
+	require.Equal(t, "code=ERR", string(data))
+
+	result := &testDetails{}
+	require.NoError(t, m.Unmarshal([]byte("code=ERR&details=a"), result))
+	require.Equal(t, "ERR", result.Code)
+	require.Nil(t, result.Details)
+}
@@ -0,0 +1,36 @@
+package marshaler
+
+import (
+	"bytes"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgPackMarshaler transforms the entities in MessagePack format. The struct
+// fields are named with the "json" tags, so the same entities can be used with
+// the JSON marshaler.
+type MsgPackMarshaler struct {
+}
+
+func (m *MsgPackMarshaler) ContentTypeHeader() string {
+	return "application/msgpack"
+}
+
+func (m *MsgPackMarshaler) Unmarshal(data []byte, entity interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(entity)
+}
+
+func (m *MsgPackMarshaler) Marshal(entity interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+
+	err := enc.Encode(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
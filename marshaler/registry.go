@@ -0,0 +1,191 @@
+package marshaler
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry is a set of marshalers keyed by media type. It chooses the
+// marshaler to read and write the HTTP requests and responses.
+type Registry struct {
+	mu sync.RWMutex
+
+	def        Marshaler
+	marshalers map[string]Marshaler
+	mediaTypes []string
+}
+
+// NewRegistry creates a new registry with the marshalers provided. The first
+// marshaler is the default one.
+func NewRegistry(marshalers ...Marshaler) *Registry {
+	r := &Registry{
+		marshalers: make(map[string]Marshaler),
+		mediaTypes: make([]string, 0),
+	}
+
+	for _, m := range marshalers {
+		r.Register(m)
+	}
+
+	return r
+}
+
+// Register adds the marshaler for its media type. It replaces the marshaler if
+// the media type already exists. The first marshaler registered is the default
+// one.
+func (r *Registry) Register(m Marshaler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mediaType := normalizeMediaType(m.ContentTypeHeader())
+	if _, ok := r.marshalers[mediaType]; !ok {
+		r.mediaTypes = append(r.mediaTypes, mediaType)
+	}
+	r.marshalers[mediaType] = m
+
+	if r.def == nil || normalizeMediaType(r.def.ContentTypeHeader()) == mediaType {
+		r.def = m
+	}
+}
+
+// SetDefault registers the marshaler and sets it as the default one.
+func (r *Registry) SetDefault(m Marshaler) {
+	r.Register(m)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.def = m
+}
+
+// Default returns the default marshaler.
+func (r *Registry) Default() Marshaler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.def
+}
+
+// Get returns the marshaler for the media type provided (e.g. the request
+// "Content-Type" header). The media types with a structured syntax suffix (e.g.
+// "application/problem+json") use the marshaler of the suffix if there is not
+// a specific one.
+func (r *Registry) Get(mediaType string) (Marshaler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mediaType = normalizeMediaType(mediaType)
+	if m, ok := r.marshalers[mediaType]; ok {
+		return m, true
+	}
+
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		m, ok := r.marshalers["application/"+mediaType[i+1:]]
+		return m, ok
+	}
+
+	return nil, false
+}
+
+// Negotiate returns the marshaler that best matches the "Accept" header value
+// provided according to the quality values. It returns the default marshaler if
+// the value is empty.
+func (r *Registry) Negotiate(accept string) (Marshaler, bool) {
+	if strings.TrimSpace(accept) == "" {
+		m := r.Default()
+		return m, m != nil
+	}
+
+	for _, mediaRange := range parseAccept(accept) {
+		if m, ok := r.match(mediaRange); ok {
+			return m, true
+		}
+	}
+
+	return nil, false
+}
+
+// match returns the marshaler for the media range provided. The default
+// marshaler has preference for the wildcard ranges.
+func (r *Registry) match(mediaRange string) (Marshaler, bool) {
+	if mediaRange == "*/*" {
+		m := r.Default()
+		return m, m != nil
+	}
+
+	if strings.HasSuffix(mediaRange, "/*") {
+		prefix := strings.TrimSuffix(mediaRange, "*")
+
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		if r.def != nil && strings.HasPrefix(normalizeMediaType(r.def.ContentTypeHeader()), prefix) {
+			return r.def, true
+		}
+
+		for _, mediaType := range r.mediaTypes {
+			if strings.HasPrefix(mediaType, prefix) {
+				return r.marshalers[mediaType], true
+			}
+		}
+
+		return nil, false
+	}
+
+	return r.Get(mediaRange)
+}
+
+// parseAccept returns the media ranges in the "Accept" header value sorted by
+// preference. The media ranges with quality 0 are not included.
+func parseAccept(accept string) []string {
+	type mediaRange struct {
+		value   string
+		quality float64
+	}
+
+	ranges := []mediaRange{}
+	for _, part := range strings.Split(accept, ",") {
+		value, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{value: value, quality: quality})
+		}
+	}
+
+	// The most specific ranges have preference if the quality is the same.
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return strings.Count(ranges[i].value, "*") < strings.Count(ranges[j].value, "*")
+	})
+
+	result := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		result = append(result, r.value)
+	}
+
+	return result
+}
+
+// normalizeMediaType returns the media type without parameters in lower case.
+func normalizeMediaType(value string) string {
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(value, ";")
+	}
+
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
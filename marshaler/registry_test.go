@@ -0,0 +1,78 @@
+package marshaler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testMarshaler struct {
+	JSONMarshaler
+	contentType string
+}
+
+func (m *testMarshaler) ContentTypeHeader() string {
+	return m.contentType
+}
+
+func TestRegistryGetsMarshalerByMediaType(t *testing.T) {
+	xml := &testMarshaler{contentType: "application/xml"}
+	r := NewRegistry(&JSONMarshaler{}, xml)
+
+	m, ok := r.Get("application/xml; charset=utf-8")
+	require.True(t, ok)
+	require.Equal(t, xml, m)
+
+	m, ok = r.Get("application/problem+json")
+	require.True(t, ok)
+	require.Equal(t, &JSONMarshaler{}, m)
+
+	_, ok = r.Get("text/plain")
+	require.False(t, ok)
+}
+
+func TestRegistryNegotiatesWithQualityValues(t *testing.T) {
+	json := &JSONMarshaler{}
+	xml := &testMarshaler{contentType: "application/xml"}
+	text := &testMarshaler{contentType: "text/plain"}
+	r := NewRegistry(json, xml, text)
+
+	tests := []struct {
+		accept   string
+		expected Marshaler
+	}{
+		{accept: "", expected: json},
+		{accept: "*/*", expected: json},
+		{accept: "application/xml", expected: xml},
+		{accept: "application/json;q=0.5, application/xml", expected: xml},
+		{accept: "application/json;q=0.5, application/xml;q=0.8", expected: xml},
+		{accept: "text/*, application/json;q=0.1", expected: text},
+		{accept: "image/png, */*;q=0.1", expected: json},
+		{accept: "application/*;q=0.5, application/xml;q=0.5", expected: xml},
+	}
+
+	for _, test := range tests {
+		m, ok := r.Negotiate(test.accept)
+		require.True(t, ok, test.accept)
+		require.Equal(t, test.expected, m, test.accept)
+	}
+}
+
+func TestRegistryNegotiationFailsWithUnsupportedMediaTypes(t *testing.T) {
+	r := NewRegistry(&JSONMarshaler{})
+
+	_, ok := r.Negotiate("image/png, application/json;q=0")
+	require.False(t, ok)
+}
+
+func TestRegistrySetsDefaultMarshaler(t *testing.T) {
+	xml := &testMarshaler{contentType: "application/xml"}
+	r := NewRegistry(&JSONMarshaler{})
+	r.SetDefault(xml)
+
+	require.Equal(t, xml, r.Default())
+
+	m, ok := r.Get("application/json")
+	require.True(t, ok)
+	require.Equal(t, &JSONMarshaler{}, m)
+}
@@ -0,0 +1,19 @@
+package marshaler
+
+import "encoding/xml"
+
+// XMLMarshaler transforms the entities with the "encoding/xml" package.
+type XMLMarshaler struct {
+}
+
+func (m *XMLMarshaler) ContentTypeHeader() string {
+	return "application/xml"
+}
+
+func (m *XMLMarshaler) Unmarshal(data []byte, entity interface{}) error {
+	return xml.Unmarshal(data, entity)
+}
+
+func (m *XMLMarshaler) Marshal(entity interface{}) ([]byte, error) {
+	return xml.Marshal(entity)
+}
@@ -0,0 +1,159 @@
+package capo
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+func TestResponseSetsContentType(t *testing.T) {
+	serverHandler := New()
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: "hello"})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html;q=0.9, application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, (&marshaler.JSONMarshaler{}).ContentTypeHeader(), res.Header.Get("Content-Type"))
+}
+
+func TestResponseIsNotAcceptable(t *testing.T) {
+	serverHandler := New()
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: "hello"})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body := map[string]any{}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotAcceptable, res.StatusCode)
+	require.Equal(t, NotAcceptableErrorCode, body["code"])
+}
+
+func TestRequestWithUnsupportedMediaType(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	serverHandler.Post("/", func(ctx *Context) error {
+		return ctx.Read(&TestData{})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Post(s.URL, "text/csv", strings.NewReader("msg\nhello"))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body := map[string]any{}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+	require.Equal(t, UnsupportedMediaTypeErrorCode, body["code"])
+}
+
+func TestRequestWithContentTypeParameters(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	msg := "hello"
+	serverHandler.Post("/", func(ctx *Context) error {
+		req := &TestData{}
+		err := ctx.Read(req)
+		require.NoError(t, err)
+		require.Equal(t, msg, req.Message)
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Post(s.URL, "application/json; charset=utf-8", strings.NewReader(`{"msg":"hello"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestRequestWithInvalidBody(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	serverHandler.Post("/", func(ctx *Context) error {
+		return ctx.Read(&TestData{})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Post(s.URL, "application/json", strings.NewReader("{bad"))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body := map[string]any{}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, BadRequestErrorCode, body["code"])
+}
+
+func TestErrorResponseWithFormMarshaler(t *testing.T) {
+	serverHandler := New()
+	serverHandler.SetMarshalers(&marshaler.JSONMarshaler{}, &marshaler.FormMarshaler{})
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	serverHandler.Post("/", func(ctx *Context) error {
+		return Validate(&TestValidationData{})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/x-www-form-urlencoded")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	require.Equal(t, "application/x-www-form-urlencoded", res.Header.Get("Content-Type"))
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	body, err := url.ParseQuery(string(data))
+	require.NoError(t, err)
+	require.Equal(t, ValidationErrorCode, body.Get("Code"))
+}
@@ -0,0 +1,77 @@
+package capo
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Param returns the value of the path parameter provided. It returns an empty
+// string if the parameter does not exist.
+func (ctx *Context) Param(key string) string {
+	return mux.Vars(ctx.r)[key]
+}
+
+// ParamInt returns the value of the path parameter provided as an integer.
+func (ctx *Context) ParamInt(key string) (int, error) {
+	value, ok := mux.Vars(ctx.r)[key]
+	if !ok {
+		return 0, newParamError("path", key, fmt.Errorf("the parameter is missing"))
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, newParamError("path", key, err)
+	}
+
+	return result, nil
+}
+
+// ParamUUID returns the value of the path parameter provided as an UUID.
+func (ctx *Context) ParamUUID(key string) (uuid.UUID, error) {
+	value, ok := mux.Vars(ctx.r)[key]
+	if !ok {
+		return uuid.Nil, newParamError("path", key, fmt.Errorf("the parameter is missing"))
+	}
+
+	result, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, newParamError("path", key, err)
+	}
+
+	return result, nil
+}
+
+// Query returns the first value of the query string parameter provided. It
+// returns an empty string if the parameter does not exist.
+func (ctx *Context) Query(key string) string {
+	return ctx.r.URL.Query().Get(key)
+}
+
+// QueryInt returns the value of the query string parameter provided as an
+// integer. It returns the default value if the parameter does not exist.
+func (ctx *Context) QueryInt(key string, defaultValue int) (int, error) {
+	value := ctx.r.URL.Query().Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, newParamError("query", key, err)
+	}
+
+	return result, nil
+}
+
+// QueryAll returns all the values of the query string parameter provided.
+func (ctx *Context) QueryAll(key string) []string {
+	return ctx.r.URL.Query()[key]
+}
+
+// newParamError creates the server error for an invalid request parameter.
+func newParamError(source string, key string, err error) *ServerError {
+	return NewServerError(InvalidParamErrorCode, fmt.Errorf("invalid %s parameter %q :: %w", source, key, err))
+}
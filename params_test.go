@@ -0,0 +1,87 @@
+package capo_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo"
+	"github.com/tonygcs/capo/capotest"
+	"github.com/tonygcs/capo/client"
+)
+
+func TestContextReadsPathParams(t *testing.T) {
+	serverHandler := capo.New()
+
+	id := uuid.New()
+	serverHandler.Get("/users/{id}/items/{item}", func(ctx *capo.Context) error {
+		require.Equal(t, id.String(), ctx.Param("id"))
+		require.Empty(t, ctx.Param("missing"))
+
+		userID, err := ctx.ParamUUID("id")
+		require.NoError(t, err)
+		require.Equal(t, id, userID)
+
+		item, err := ctx.ParamInt("item")
+		require.NoError(t, err)
+		require.Equal(t, 42, item)
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	err := client.NewRequest().URL(s.URL).RelativePath("users/" + id.String() + "/items/42").Do(nil)
+	require.NoError(t, err)
+}
+
+func TestContextReturnsServerErrorOnInvalidPathParams(t *testing.T) {
+	serverHandler := capo.New()
+
+	serverHandler.Get("/users/{id}", func(ctx *capo.Context) error {
+		_, err := ctx.ParamInt("id")
+		capotest.RequireErrorCode(t, err, capo.InvalidParamErrorCode)
+
+		_, err = ctx.ParamUUID("id")
+		capotest.RequireErrorCode(t, err, capo.InvalidParamErrorCode)
+
+		_, err = ctx.ParamInt("missing")
+		capotest.RequireErrorCode(t, err, capo.InvalidParamErrorCode)
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	err := client.NewRequest().URL(s.URL).RelativePath("users/invalid").Do(nil)
+	require.NoError(t, err)
+}
+
+func TestContextReadsQueryParams(t *testing.T) {
+	serverHandler := capo.New()
+
+	serverHandler.Get("/", func(ctx *capo.Context) error {
+		require.Equal(t, "name", ctx.Query("sort"))
+		require.Equal(t, []string{"a", "b"}, ctx.QueryAll("tag"))
+		require.Empty(t, ctx.QueryAll("missing"))
+
+		page, err := ctx.QueryInt("page", 1)
+		require.NoError(t, err)
+		require.Equal(t, 3, page)
+
+		limit, err := ctx.QueryInt("limit", 20)
+		require.NoError(t, err)
+		require.Equal(t, 20, limit)
+
+		_, err = ctx.QueryInt("sort", 0)
+		capotest.RequireErrorCode(t, err, capo.InvalidParamErrorCode)
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	err := client.NewRequest().URL(s.URL + "?sort=name&tag=a&tag=b&page=3").Do(nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,154 @@
+package capo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the media type of the RFC 7807 problem documents.
+const ProblemContentType = "application/problem+json"
+
+// problemMembers are the members defined by the RFC 7807.
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// Problem is a RFC 7807 problem details document.
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+
+	// Extensions are the additional members of the problem document.
+	Extensions map[string]any
+}
+
+// MarshalJSON implements "json.Marshaler" interface. The extensions are
+// included as members of the document.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]any, len(p.Extensions)+len(problemMembers))
+	for key, value := range p.Extensions {
+		doc[key] = value
+	}
+
+	doc["type"] = p.Type
+	doc["title"] = p.Title
+	doc["status"] = p.Status
+	if p.Detail != "" {
+		doc["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		doc["instance"] = p.Instance
+	}
+
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON implements "json.Unmarshaler" interface. The members that are
+// not defined by the RFC 7807 are set as extensions.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	doc := struct {
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+	}{}
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return err
+	}
+
+	extensions := map[string]any{}
+	err = json.Unmarshal(data, &extensions)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range problemMembers {
+		delete(extensions, member)
+	}
+
+	*p = Problem{
+		Type:       doc.Type,
+		Title:      doc.Title,
+		Status:     doc.Status,
+		Detail:     doc.Detail,
+		Instance:   doc.Instance,
+		Extensions: extensions,
+	}
+	return nil
+}
+
+// ProblemConfig is the configuration for the problem error handling
+// middleware.
+type ProblemConfig struct {
+	// TypeBaseURI is the base URI of the problem types. The error code is
+	// appended to it to build the problem type. If it is empty, the problem
+	// type is "about:blank".
+	TypeBaseURI string
+	// Debug includes the inner error message in the response. It must not be
+	// enabled in production environments.
+	Debug bool
+	// Extensions returns the additional members for the problem document.
+	Extensions func(ctx *Context, err *ServerError) map[string]any
+}
+
+// ProblemErrorHandling writes the context error in the response as a RFC 7807
+// problem document with the default configuration.
+func ProblemErrorHandling(ctx *Context) {
+	defaultProblemErrorHandling(ctx)
+}
+
+var defaultProblemErrorHandling = NewProblemErrorHandling(ProblemConfig{})
+
+// NewProblemErrorHandling returns the middleware that writes the context error
+// in the response as a RFC 7807 problem document. The error code and details
+// are included as the "code" and "details" extension members. The request id
+// set by the "CreateLog" middleware is used as the problem instance. The
+// document is encoded as JSON even if the client accepts other formats.
+func NewProblemErrorHandling(config ProblemConfig) func(*Context) {
+	return func(ctx *Context) {
+		// The response cannot be changed if it is already sent.
+		ctxErr := ctx.Err()
+		if ctxErr == nil || ctx.Committed() {
+			return
+		}
+
+		res := responseError(ctx, ctxErr)
+
+		problem := &Problem{
+			Type:       "about:blank",
+			Title:      http.StatusText(res.Status),
+			Status:     res.Status,
+			Instance:   ctx.RequestID(),
+			Extensions: map[string]any{"code": res.Code},
+		}
+
+		if config.TypeBaseURI != "" {
+			problem.Type = strings.TrimSuffix(config.TypeBaseURI, "/") + "/" + res.Code
+		}
+
+		if res.Message != problem.Title {
+			problem.Detail = res.Message
+		}
+
+		if res.Details != nil {
+			problem.Extensions["details"] = res.Details
+		}
+
+		if config.Debug && res.inner != nil {
+			problem.Extensions["debug"] = res.inner.Error()
+		}
+
+		if config.Extensions != nil {
+			for key, value := range config.Extensions(ctx, res) {
+				problem.Extensions[key] = value
+			}
+		}
+
+		ctx.SetStatus(res.Status)
+		ctx.Write(problem)
+	}
+}
@@ -0,0 +1,110 @@
+package capo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+func TestProblemErrorHandlingWritesProblemDocument(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseBefore(CreateLog(""))
+	serverHandler.UseAfterAlways(NewProblemErrorHandling(ProblemConfig{
+		TypeBaseURI: "https://example.com/problems/",
+		Extensions: func(ctx *Context, err *ServerError) map[string]any {
+			return map[string]any{"path": ctx.Request().URL.Path}
+		},
+	}))
+
+	serverHandler.Get("/entity", func(ctx *Context) error {
+		return NewServerError(NotFoundErrorCode, errTestNotFound).WithMessage("the entity does not exist")
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL + "/entity")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	problem := &Problem{}
+	err = json.NewDecoder(res.Body).Decode(problem)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	require.Equal(t, ProblemContentType, res.Header.Get("Content-Type"))
+	require.Equal(t, "https://example.com/problems/"+NotFoundErrorCode, problem.Type)
+	require.Equal(t, http.StatusText(http.StatusNotFound), problem.Title)
+	require.Equal(t, http.StatusNotFound, problem.Status)
+	require.Equal(t, "the entity does not exist", problem.Detail)
+	require.Equal(t, res.Header.Get(defaultReqIDHeaderKey), problem.Instance)
+	require.NotEmpty(t, problem.Instance)
+	require.Equal(t, map[string]any{"code": NotFoundErrorCode, "path": "/entity"}, problem.Extensions)
+}
+
+func TestProblemErrorHandlingWritesInternalErrors(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ProblemErrorHandling)
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		return errors.New("secret database error")
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body := map[string]any{}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	require.Equal(t, map[string]any{
+		"type":   "about:blank",
+		"title":  http.StatusText(http.StatusInternalServerError),
+		"status": float64(http.StatusInternalServerError),
+		"code":   InternalServerErrorCode,
+	}, body)
+}
+
+func TestProblemErrorHandlingWritesJSONForAnyAcceptedFormat(t *testing.T) {
+	serverHandler := New()
+	serverHandler.SetMarshalers(&marshaler.JSONMarshaler{}, &marshaler.XMLMarshaler{})
+	serverHandler.UseAfterAlways(ProblemErrorHandling)
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		return NewServerError(NotFoundErrorCode, errTestNotFound)
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	for _, accept := range []string{"application/xml", "application/msgpack"} {
+		t.Run(accept, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+			require.NoError(t, err)
+			req.Header.Set("Accept", accept)
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			problem := &Problem{}
+			err = json.NewDecoder(res.Body).Decode(problem)
+			require.NoError(t, err)
+
+			require.Equal(t, http.StatusNotFound, res.StatusCode)
+			require.Equal(t, ProblemContentType, res.Header.Get("Content-Type"))
+			require.Equal(t, http.StatusNotFound, problem.Status)
+			require.Equal(t, NotFoundErrorCode, problem.Extensions["code"])
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package capo
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// anyMethod is the key used in the route table for the routes that handle
+// every HTTP method.
+const anyMethod = "*"
+
+// standardMethods are the methods allowed in the routes that handle any HTTP
+// method.
+var standardMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// routeTable keeps the HTTP methods registered for every path template. It is
+// shared by all the groups in a server.
+type routeTable struct {
+	mu      sync.RWMutex
+	methods map[string]map[string]bool
+}
+
+// newRouteTable creates a new route table instance.
+func newRouteTable() *routeTable {
+	return &routeTable{
+		methods: make(map[string]map[string]bool),
+	}
+}
+
+// add registers the method for the path provided. It returns true if it is the
+// first method registered for the path.
+func (t *routeTable) add(path string, method string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	methods, ok := t.methods[path]
+	if !ok {
+		methods = make(map[string]bool)
+		t.methods[path] = methods
+	}
+
+	methods[strings.ToUpper(method)] = true
+	return !ok
+}
+
+// has checks if the method was explicitly registered for the path provided.
+func (t *routeTable) has(path string, method string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	methods := t.methods[path]
+	return methods[strings.ToUpper(method)] || methods[anyMethod]
+}
+
+// allow returns the value for the "Allow" header of the path provided.
+func (t *routeTable) allow(path string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	methods := t.methods[path]
+	if methods[anyMethod] {
+		return strings.Join(standardMethods, ", ")
+	}
+
+	result := []string{http.MethodOptions}
+	for method := range methods {
+		if method != http.MethodOptions {
+			result = append(result, method)
+		}
+	}
+
+	// HEAD requests are served automatically for every GET route.
+	if methods[http.MethodGet] && !methods[http.MethodHead] {
+		result = append(result, http.MethodHead)
+	}
+
+	sort.Strings(result)
+	return strings.Join(result, ", ")
+}
+
+// headResponseWriter is the response writer for HEAD requests. It discards the
+// response body.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+// Flush sends the buffered data to the client.
+func (w *headResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
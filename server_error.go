@@ -1,13 +1,65 @@
 package capo
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
 
-var InternalServerErrorCode = "INTERNAL_ERROR"
+var (
+	InternalServerErrorCode       = "INTERNAL_ERROR"
+	BadRequestErrorCode           = "BAD_REQUEST"
+	InvalidParamErrorCode         = "INVALID_PARAM"
+	UnauthorizedErrorCode         = "UNAUTHORIZED"
+	ForbiddenErrorCode            = "FORBIDDEN"
+	NotFoundErrorCode             = "NOT_FOUND"
+	ConflictErrorCode             = "CONFLICT"
+	NotAcceptableErrorCode        = "NOT_ACCEPTABLE"
+	UnsupportedMediaTypeErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+)
 
-// ServerError represents a server error.
+var (
+	errorStatusesMu sync.RWMutex
+	errorStatuses   = map[string]int{
+		InternalServerErrorCode:       http.StatusInternalServerError,
+		BadRequestErrorCode:           http.StatusBadRequest,
+		InvalidParamErrorCode:         http.StatusBadRequest,
+		ValidationErrorCode:           http.StatusUnprocessableEntity,
+		UnauthorizedErrorCode:         http.StatusUnauthorized,
+		ForbiddenErrorCode:            http.StatusForbidden,
+		NotFoundErrorCode:             http.StatusNotFound,
+		ConflictErrorCode:             http.StatusConflict,
+		NotAcceptableErrorCode:        http.StatusNotAcceptable,
+		UnsupportedMediaTypeErrorCode: http.StatusUnsupportedMediaType,
+	}
+)
+
+// RegisterErrorCode sets the HTTP status for the server errors with the code
+// provided. It replaces the status if the code already exists.
+func RegisterErrorCode(code string, status int) {
+	errorStatusesMu.Lock()
+	defer errorStatusesMu.Unlock()
+	errorStatuses[code] = status
+}
+
+// ErrorCodeStatus returns the HTTP status registered for the error code
+// provided.
+func ErrorCodeStatus(code string) (int, bool) {
+	errorStatusesMu.RLock()
+	defer errorStatusesMu.RUnlock()
+	status, ok := errorStatuses[code]
+	return status, ok
+}
+
+// ServerError represents a server error. The inner error is never sent to the
+// client unless the debug mode is enabled in the error handling.
 type ServerError struct {
-	inner error
-	Code  string `json:"code"`
+	inner   error
+	Status  int    `json:"status,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+	Details any    `json:"details,omitempty"`
+	Debug   string `json:"debug,omitempty"`
 }
 
 // NewServerError creates a new instance of server error entity.
@@ -19,5 +71,58 @@ func NewServerError(code string, inner error) *ServerError {
 }
 
 func (e *ServerError) Error() string {
+	if e.inner == nil {
+		if e.Message != "" {
+			return fmt.Sprintf("%s - %s", e.Code, e.Message)
+		}
+		return e.Code
+	}
+
 	return fmt.Sprintf("%s - %s", e.Code, e.inner.Error())
 }
+
+// ErrorCode returns the error code. It allows the clients to decode the error
+// responses in a "ServerError".
+func (e *ServerError) ErrorCode() string {
+	return e.Code
+}
+
+// Unwrap returns the inner error.
+func (e *ServerError) Unwrap() error {
+	return e.inner
+}
+
+// HTTPStatus returns the HTTP status for the error. If the status is not set,
+// it returns the status registered for the error code or 500 if the code is
+// not registered.
+func (e *ServerError) HTTPStatus() int {
+	if e.Status > 0 {
+		return e.Status
+	}
+
+	if status, ok := ErrorCodeStatus(e.Code); ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// WithStatus sets the HTTP status of the error and returns itself.
+func (e *ServerError) WithStatus(status int) *ServerError {
+	e.Status = status
+	return e
+}
+
+// WithMessage sets the public message that will be sent to the client and
+// returns itself.
+func (e *ServerError) WithMessage(message string) *ServerError {
+	e.Message = message
+	return e
+}
+
+// WithDetails sets the error details that will be sent to the client and
+// returns itself.
+func (e *ServerError) WithDetails(details any) *ServerError {
+	e.Details = details
+	return e
+}
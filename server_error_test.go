@@ -0,0 +1,102 @@
+package capo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errTestNotFound = errors.New("test entity not found")
+
+func TestServerErrorSupportsErrorsIsAndAs(t *testing.T) {
+	err := fmt.Errorf("wrapped :: %w", NewServerError(NotFoundErrorCode, errTestNotFound))
+
+	require.ErrorIs(t, err, errTestNotFound)
+
+	var serverErr *ServerError
+	require.ErrorAs(t, err, &serverErr)
+	require.Equal(t, NotFoundErrorCode, serverErr.Code)
+}
+
+func TestServerErrorStatus(t *testing.T) {
+	require.Equal(t, http.StatusNotFound, NewServerError(NotFoundErrorCode, nil).HTTPStatus())
+	require.Equal(t, http.StatusTeapot, NewServerError(NotFoundErrorCode, nil).WithStatus(http.StatusTeapot).HTTPStatus())
+	require.Equal(t, http.StatusInternalServerError, NewServerError("UNKNOWN_CODE", nil).HTTPStatus())
+
+	RegisterErrorCode("TEST_PAYMENT_REQUIRED", http.StatusPaymentRequired)
+	require.Equal(t, http.StatusPaymentRequired, NewServerError("TEST_PAYMENT_REQUIRED", nil).HTTPStatus())
+}
+
+func TestErrorHandlingHonorsServerErrorStatus(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		return NewServerError(NotFoundErrorCode, errTestNotFound).
+			WithMessage("the entity does not exist").
+			WithDetails(map[string]string{"id": "42"})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, body := doTestErrorRequest(t, s.URL)
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	require.Equal(t, NotFoundErrorCode, body["code"])
+	require.Equal(t, float64(http.StatusNotFound), body["status"])
+	require.Equal(t, "the entity does not exist", body["message"])
+	require.Equal(t, map[string]any{"id": "42"}, body["details"])
+	require.NotContains(t, body, "debug")
+}
+
+func TestErrorHandlingDoesNotLeakInternalErrors(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		return errors.New("secret database error")
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, body := doTestErrorRequest(t, s.URL)
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	require.Equal(t, InternalServerErrorCode, body["code"])
+	require.Equal(t, http.StatusText(http.StatusInternalServerError), body["message"])
+	require.NotContains(t, body, "debug")
+}
+
+func TestErrorHandlingIncludesInnerErrorInDebugMode(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(NewErrorHandling(ErrorHandlingConfig{Debug: true}))
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		return errors.New("secret database error")
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, body := doTestErrorRequest(t, s.URL)
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	require.Equal(t, "secret database error", body["debug"])
+}
+
+func doTestErrorRequest(t *testing.T, url string) (*http.Response, map[string]any) {
+	t.Helper()
+
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body := map[string]any{}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	require.NoError(t, err)
+	return res, body
+}
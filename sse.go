@@ -0,0 +1,214 @@
+package capo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tonygcs/capo/internal/mediatype"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// ErrBinaryEventData is returned when the event data must be marshaled with a
+// binary format like MessagePack. The binary data would break the event lines.
+var ErrBinaryEventData = errors.New("the event data cannot be marshaled with a binary format")
+
+// EventStreamContentType is the content type of the Server-Sent Events
+// responses.
+const EventStreamContentType = "text/event-stream"
+
+// defaultHeartbeat is the default time between the heartbeat comments.
+const defaultHeartbeat = 15 * time.Second
+
+// SSEOption is an option to configure a Server-Sent Events stream.
+type SSEOption func(*sseConfig)
+
+type sseConfig struct {
+	heartbeat time.Duration
+}
+
+// WithHeartbeat sets the time between the heartbeat comments that keep the
+// connection alive. Zero disables them. The default value is 15 seconds.
+func WithHeartbeat(d time.Duration) SSEOption {
+	return func(c *sseConfig) { c.heartbeat = d }
+}
+
+// EventStream is a Server-Sent Events stream. It is safe to send events from
+// different goroutines.
+type EventStream struct {
+	mu  sync.Mutex
+	ctx *Context
+	m   marshaler.Marshaler
+}
+
+// SSE sends a Server-Sent Events response and calls the function provided to
+// send the events. The stream is closed when the function returns. If the
+// client disconnects, the context is done and the function must return; the
+// error is ignored in that case.
+//
+//	return ctx.SSE(func(stream *capo.EventStream) error {
+//		for {
+//			select {
+//			case <-stream.Done():
+//				return nil
+//			case update := <-updates:
+//				if err := stream.Send("update", update.ID, update); err != nil {
+//					return err
+//				}
+//			}
+//		}
+//	})
+func (ctx *Context) SSE(fn func(stream *EventStream) error, opts ...SSEOption) error {
+	config := &sseConfig{heartbeat: defaultHeartbeat}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	// Fail before sending the response, so it can be handled.
+	if !ctx.w.canFlush() {
+		return ErrFlushNotSupported
+	}
+
+	ctx.headers["Content-Type"] = EventStreamContentType
+	ctx.headers["Cache-Control"] = "no-cache"
+	ctx.headers["X-Accel-Buffering"] = "no"
+
+	// The event data is marshaled with the accepted format or the default one
+	// of the route, since the clients usually accept "text/event-stream".
+	m, err := ctx.ResponseMarshaler()
+	if err != nil {
+		m = ctx.marshalers().Default()
+	}
+
+	stream := &EventStream{ctx: ctx, m: m}
+	if err := ctx.Flush(); err != nil {
+		return err
+	}
+
+	// Send the heartbeat comments until the function returns.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	if config.heartbeat > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stream.heartbeat(config.heartbeat, done)
+		}()
+	}
+
+	err = fn(stream)
+	close(done)
+	wg.Wait()
+
+	// The client is disconnected.
+	if ctx.Context().Err() != nil {
+		return nil
+	}
+
+	return err
+}
+
+// LastEventID returns the id of the last event received by the client. It is
+// sent in the "Last-Event-ID" header when the client reconnects.
+func (s *EventStream) LastEventID() string {
+	return s.ctx.r.Header.Get("Last-Event-ID")
+}
+
+// Done returns a channel that is closed when the client disconnects.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send sends an event to the client. The event name and id are optional. The
+// data is marshaled with the marshaler accepted by the client or the default
+// one of the route, except the strings and bytes that are sent as they are. It
+// returns "ErrBinaryEventData" if the marshaler format is not a text one.
+func (s *EventStream) Send(event string, id string, data any) error {
+	var content []byte
+	switch d := data.(type) {
+	case string:
+		content = []byte(d)
+	case []byte:
+		content = d
+	default:
+		if !mediatype.IsText(s.m.ContentTypeHeader()) {
+			return ErrBinaryEventData
+		}
+
+		marshaled, err := s.m.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("cannot marshal the event data :: %w", err)
+		}
+		content = marshaled
+	}
+
+	buf := &bytes.Buffer{}
+	if event != "" {
+		fmt.Fprintf(buf, "event: %s\n", sanitizeField(event))
+	}
+	if id != "" {
+		fmt.Fprintf(buf, "id: %s\n", sanitizeField(id))
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		fmt.Fprintf(buf, "data: %s\n", line)
+	}
+	buf.WriteString("\n")
+
+	return s.write(buf.Bytes())
+}
+
+// Comment sends a comment to the client. The clients ignore the comments.
+func (s *EventStream) Comment(text string) error {
+	return s.write([]byte(": " + sanitizeField(text) + "\n\n"))
+}
+
+// Retry sets the time the client waits before reconnecting.
+func (s *EventStream) Retry(d time.Duration) error {
+	return s.write([]byte("retry: " + strconv.FormatInt(d.Milliseconds(), 10) + "\n\n"))
+}
+
+// write sends the data provided to the client and flushes it.
+func (s *EventStream) write(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.Context().Err(); err != nil {
+		return err
+	}
+
+	if err := s.ctx.WriteBytes(data); err != nil {
+		return err
+	}
+
+	return s.ctx.Flush()
+}
+
+// heartbeat sends a comment every time interval until the channel is closed or
+// the client disconnects.
+func (s *EventStream) heartbeat(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-s.Done():
+			return
+		case <-ticker.C:
+			if err := s.Comment("heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sanitizeField removes the line breaks of an event field, so it cannot break
+// the event format.
+func sanitizeField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,215 @@
+package capo
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/client"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+func TestContextSSE(t *testing.T) {
+	serverHandler := New()
+
+	serverHandler.Get("/events", func(ctx *Context) error {
+		return ctx.SSE(func(stream *EventStream) error {
+			require.NoError(t, stream.Send("update", "1", &TestData{Message: "first"}))
+			require.NoError(t, stream.Send("", "2", "plain\ntext"))
+			return nil
+		})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := client.New(s.URL).Get("events").DoStream()
+	require.NoError(t, err)
+	defer res.Close()
+	require.Equal(t, EventStreamContentType, res.Header.Get("Content-Type"))
+	require.Equal(t, "no-cache", res.Header.Get("Cache-Control"))
+
+	events := res.Events()
+
+	event, err := events.Next()
+	require.NoError(t, err)
+	require.Equal(t, "update", event.Event)
+	require.Equal(t, "1", event.ID)
+
+	data := &TestData{}
+	require.NoError(t, event.Read(data))
+	require.Equal(t, "first", data.Message)
+
+	event, err = events.Next()
+	require.NoError(t, err)
+	require.Equal(t, "message", event.Event)
+	require.Equal(t, "2", event.ID)
+	require.Equal(t, "plain\ntext", string(event.Data))
+
+	_, err = events.Next()
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, "2", events.LastEventID())
+}
+
+func TestContextSSEUsesRouteMarshaler(t *testing.T) {
+	serverHandler := New()
+	serverHandler.SetMarshalers(&marshaler.XMLMarshaler{})
+
+	serverHandler.Get("/events", func(ctx *Context) error {
+		return ctx.SSE(func(stream *EventStream) error {
+			return stream.Send("update", "1", &TestData{Message: "xml"})
+		})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	// The event stream media type is accepted, so the default marshaler of the
+	// route is used.
+	res, err := client.New(s.URL, client.WithMarshaler(&marshaler.XMLMarshaler{})).
+		Get("events").
+		AddHeader("Accept", EventStreamContentType).
+		DoStream()
+	require.NoError(t, err)
+	defer res.Close()
+
+	event, err := res.Events().Next()
+	require.NoError(t, err)
+	require.Equal(t, "<TestData><Message>xml</Message></TestData>", string(event.Data))
+
+	data := &TestData{}
+	require.NoError(t, event.Read(data))
+	require.Equal(t, "xml", data.Message)
+}
+
+func TestContextSSERefusesBinaryMarshalers(t *testing.T) {
+	serverHandler := New()
+	serverHandler.SetMarshalers(&marshaler.MsgPackMarshaler{})
+
+	serverHandler.Get("/events", func(ctx *Context) error {
+		return ctx.SSE(func(stream *EventStream) error {
+			require.ErrorIs(t, stream.Send("update", "1", &TestData{Message: "binary"}), ErrBinaryEventData)
+			return stream.Send("update", "2", "text")
+		})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := client.New(s.URL, client.WithMarshaler(&marshaler.MsgPackMarshaler{})).Get("events").DoStream()
+	require.NoError(t, err)
+	defer res.Close()
+
+	event, err := res.Events().Next()
+	require.NoError(t, err)
+	require.Equal(t, "2", event.ID)
+	require.Equal(t, "text", string(event.Data))
+}
+
+func TestContextSSELastEventID(t *testing.T) {
+	serverHandler := New()
+
+	serverHandler.Get("/events", func(ctx *Context) error {
+		return ctx.SSE(func(stream *EventStream) error {
+			return stream.Send("", "", stream.LastEventID())
+		})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := client.New(s.URL).Get("events").AddHeader("Last-Event-ID", "41").DoStream()
+	require.NoError(t, err)
+	defer res.Close()
+
+	event, err := res.Events().Next()
+	require.NoError(t, err)
+	require.Equal(t, "41", string(event.Data))
+}
+
+func TestContextSSEHeartbeat(t *testing.T) {
+	serverHandler := New()
+
+	serverHandler.Get("/events", func(ctx *Context) error {
+		return ctx.SSE(func(stream *EventStream) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}, WithHeartbeat(10*time.Millisecond))
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL + "/events")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": heartbeat\n", line)
+}
+
+func TestContextSSEStopsOnDisconnect(t *testing.T) {
+	serverHandler := New()
+
+	stopped := make(chan error, 1)
+	serverHandler.UseAfterAlways(func(ctx *Context) {
+		stopped <- ctx.Err()
+	})
+	serverHandler.Get("/events", func(ctx *Context) error {
+		return ctx.SSE(func(stream *EventStream) error {
+			for {
+				select {
+				case <-stream.Done():
+					return context.Canceled
+				case <-time.After(5 * time.Millisecond):
+					if err := stream.Send("", "", "tick"); err != nil {
+						return err
+					}
+				}
+			}
+		})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res, err := client.New(s.URL).Get("events").DoStreamContext(ctx)
+	require.NoError(t, err)
+
+	_, err = res.Events().Next()
+	require.NoError(t, err)
+
+	cancel()
+	res.Close()
+
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "the stream is not stopped")
+	}
+}
+
+func TestContextSSEWithoutFlusher(t *testing.T) {
+	ctx := NewContext(&nonFlusherWriter{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	err := ctx.SSE(func(stream *EventStream) error { return nil })
+	require.ErrorIs(t, err, ErrFlushNotSupported)
+	require.False(t, ctx.Committed())
+}
+
+// nonFlusherWriter is a response writer that does not support flushing.
+type nonFlusherWriter struct {
+	w *httptest.ResponseRecorder
+}
+
+func (w *nonFlusherWriter) Header() http.Header         { return w.w.Header() }
+func (w *nonFlusherWriter) Write(b []byte) (int, error) { return w.w.Write(b) }
+func (w *nonFlusherWriter) WriteHeader(status int)      { w.w.WriteHeader(status) }
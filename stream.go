@@ -0,0 +1,174 @@
+package capo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+var (
+	// ErrFlushNotSupported indicates the response writer cannot flush the data.
+	ErrFlushNotSupported = errors.New("the response writer does not support flushing")
+	// ErrHijackNotSupported indicates the response writer cannot hijack the
+	// connection.
+	ErrHijackNotSupported = errors.New("the response writer does not support hijacking")
+)
+
+// responseWriter is the response writer of the context. It keeps the status and
+// the number of bytes written in the response.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	written     int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.status = status
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	n, err := w.ResponseWriter.Write(data)
+
+	// The body of the HEAD responses is discarded, so it is not sent.
+	if _, ok := w.ResponseWriter.(*headResponseWriter); !ok {
+		w.written += int64(n)
+	}
+
+	return n, err
+}
+
+// Flush sends the buffered data to the client.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, e.g. to upgrade it to a
+// WebSocket connection. The response is committed once it is hijacked.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrHijackNotSupported
+	}
+
+	conn, rw, err := h.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w.status = http.StatusSwitchingProtocols
+	w.wroteHeader = true
+	return conn, rw, nil
+}
+
+// Unwrap returns the original response writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// canFlush checks if the original response writer supports flushing.
+func (w *responseWriter) canFlush() bool {
+	_, ok := w.ResponseWriter.(http.Flusher)
+	return ok
+}
+
+// Committed checks if the response status and headers are already sent to the
+// client. The status, headers and data set after it are ignored.
+func (ctx *Context) Committed() bool {
+	return ctx.w.wroteHeader
+}
+
+// BytesWritten returns the number of bytes of the response body sent to the
+// client. It is 0 for the HEAD requests because their body is discarded.
+func (ctx *Context) BytesWritten() int64 {
+	return ctx.w.written
+}
+
+// Stream sends the response status and headers and calls the function provided
+// to write the response body directly. The content type is set unless the
+// "Content-Type" header is already set.
+func (ctx *Context) Stream(contentType string, fn func(w io.Writer) error) error {
+	if contentType != "" {
+		if _, ok := ctx.headers["Content-Type"]; !ok {
+			ctx.headers["Content-Type"] = contentType
+		}
+	}
+
+	ctx.commit()
+	return fn(ctx.w)
+}
+
+// WriteBytes sends the response status and headers and writes the data provided
+// in the response body.
+func (ctx *Context) WriteBytes(data []byte) error {
+	ctx.commit()
+
+	if _, err := ctx.w.Write(data); err != nil {
+		return fmt.Errorf("cannot write the response :: %w", err)
+	}
+
+	return nil
+}
+
+// WriteReader sends the response status and headers and copies the reader
+// content in the response body. It returns the number of bytes copied.
+func (ctx *Context) WriteReader(r io.Reader) (int64, error) {
+	ctx.commit()
+
+	n, err := io.Copy(ctx.w, r)
+	if err != nil {
+		return n, fmt.Errorf("cannot write the response :: %w", err)
+	}
+
+	return n, nil
+}
+
+// Flush sends the response status, headers and the data written to the client.
+// It returns "ErrFlushNotSupported" if the response writer cannot flush the
+// data.
+func (ctx *Context) Flush() error {
+	ctx.commit()
+
+	if !ctx.w.canFlush() {
+		return ErrFlushNotSupported
+	}
+
+	ctx.w.Flush()
+	return nil
+}
+
+// OnClose adds a function that runs once the response is written. The
+// middlewares that run after the request can use it to see the final status
+// and the bytes written.
+func (ctx *Context) OnClose(fn func(*Context)) {
+	ctx.onClose = append(ctx.onClose, fn)
+}
+
+// commit sends the response status and headers if they are not sent yet.
+func (ctx *Context) commit() {
+	if ctx.Committed() {
+		return
+	}
+
+	// Add the headers. They must be set before the status code is written.
+	for key, value := range ctx.headers {
+		ctx.w.Header().Add(key, value)
+	}
+
+	ctx.w.WriteHeader(ctx.Status())
+}
@@ -0,0 +1,187 @@
+package capo
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextStream(t *testing.T) {
+	serverHandler := New()
+
+	next := make(chan struct{})
+	serverHandler.Get("/", func(ctx *Context) error {
+		return ctx.Stream("application/x-ndjson", func(w io.Writer) error {
+			io.WriteString(w, "first\n")
+			require.NoError(t, ctx.Flush())
+
+			// Wait until the client reads the first line.
+			<-next
+			io.WriteString(w, "second\n")
+			return nil
+		})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(res.Body)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "first\n", line)
+
+	close(next)
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "second\n", line)
+}
+
+func TestContextWriteBytesAndReader(t *testing.T) {
+	serverHandler := New()
+
+	var status int
+	var written int64
+	serverHandler.UseAfterAlways(func(ctx *Context) {
+		status = ctx.Status()
+		written = ctx.BytesWritten()
+	})
+	serverHandler.Get("/", func(ctx *Context) error {
+		ctx.AddHeader("Content-Type", "text/plain")
+		ctx.SetStatus(http.StatusAccepted)
+		require.NoError(t, ctx.WriteBytes([]byte("hello ")))
+
+		n, err := ctx.WriteReader(strings.NewReader("world"))
+		require.NoError(t, err)
+		require.Equal(t, int64(5), n)
+
+		// The response is already committed.
+		ctx.SetStatus(http.StatusCreated)
+		ctx.Write(&TestData{Message: "ignored"})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+	require.Equal(t, "text/plain", res.Header.Get("Content-Type"))
+	require.Equal(t, "hello world", string(body))
+
+	require.Equal(t, http.StatusAccepted, status)
+	require.Equal(t, int64(11), written)
+}
+
+func TestErrorHandlingSkipsCommittedResponse(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		ctx.WriteBytes([]byte("partial"))
+		return errors.New("stream failed")
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "partial", string(body))
+}
+
+func TestContextOnCloseSeesWrittenResponse(t *testing.T) {
+	serverHandler := New()
+
+	var status int
+	var written int64
+	serverHandler.UseAfterAlways(func(ctx *Context) {
+		ctx.OnClose(func(ctx *Context) {
+			status = ctx.Status()
+			written = ctx.BytesWritten()
+		})
+	})
+	serverHandler.Get("/", func(ctx *Context) error {
+		ctx.SetStatus(http.StatusCreated)
+		ctx.Write(&TestData{Message: "hello"})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, int64(len(body)), written)
+}
+
+func TestContextBytesWrittenOnHeadRequest(t *testing.T) {
+	serverHandler := New()
+
+	written := make(chan int64, 1)
+	serverHandler.UseAfterAlways(func(ctx *Context) {
+		ctx.OnClose(func(ctx *Context) {
+			written <- ctx.BytesWritten()
+		})
+	})
+	serverHandler.Get("/", func(ctx *Context) error {
+		ctx.Write(&TestData{Message: "hello"})
+		return nil
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Head(s.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, int64(0), <-written)
+}
+
+func TestLogRequestStreamedResponse(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseBefore(CreateLog(""))
+	serverHandler.UseAfterAlways(LogRequest)
+
+	serverHandler.Get("/", func(ctx *Context) error {
+		return ctx.WriteBytes([]byte("streamed"))
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NotEmpty(t, res.Header.Get(defaultReqIDHeaderKey))
+}
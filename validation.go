@@ -0,0 +1,277 @@
+package capo
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ValidationErrorCode is the error code for the entities that do not pass the
+// validation rules.
+var ValidationErrorCode = "VALIDATION_ERROR"
+
+// ValidatorFunc checks a field value. It receives the rule parameter (e.g. "3"
+// in "min=3") and returns an error with the message for the client if the
+// value is not valid.
+type ValidatorFunc func(value reflect.Value, param string) error
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = map[string]ValidatorFunc{
+		"min":   validateMin,
+		"max":   validateMax,
+		"len":   validateLen,
+		"oneof": validateOneOf,
+		"email": validateEmail,
+	}
+)
+
+// RegisterValidator adds a validation rule that can be used in the "validate"
+// struct tag. It replaces the rule if it already exists.
+func RegisterValidator(name string, fn ValidatorFunc) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[name] = fn
+}
+
+// ReadValid takes the information in the request body, unmarshal the data in
+// the entity provided and validates it. Like "Validate", the rules of the
+// fields with a zero value are skipped unless the field is required.
+func (ctx *Context) ReadValid(entity any) error {
+	err := ctx.Read(entity)
+	if err != nil {
+		return err
+	}
+
+	return Validate(entity)
+}
+
+// Validate checks the entity fields with the rules in the "validate" struct
+// tag. e.g.
+//
+//	type Input struct {
+//		Name string `json:"name" validate:"required,min=3,max=64"`
+//		Kind string `json:"kind" validate:"oneof=a b"`
+//		Mail string `json:"mail" validate:"email"`
+//	}
+//
+// The rules are only checked for the fields with a value, so the fields with a
+// zero value (e.g. 0, "" or nil) skip every rule except "required". e.g.
+// "min=1" accepts 0 and "oneof=a b" accepts "" unless the field is also
+// required. It returns a server error with the fields that are not valid.
+func Validate(entity any) error {
+	v := reflect.ValueOf(entity)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errs := FieldErrors{}
+	validateStruct(v, "", &errs)
+	if len(errs) > 0 {
+		return NewServerError(ValidationErrorCode, errs).WithDetails(errs)
+	}
+
+	return nil
+}
+
+// validateStruct checks the struct fields. The errors found are included in the
+// list provided.
+func validateStruct(v reflect.Value, prefix string, errs *FieldErrors) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		// Validate the embedded structs fields as part of the current struct.
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			validateStruct(fieldValue, prefix, errs)
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		if tag, ok := field.Tag.Lookup("validate"); ok && tag != "-" {
+			validateField(fieldValue, name, tag, errs)
+		}
+
+		validateNested(fieldValue, name, errs)
+	}
+}
+
+// validateNested checks the structs inside the field provided.
+func validateNested(v reflect.Value, name string, errs *FieldErrors) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		validateStruct(v, name+".", errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateNested(v.Index(i), fmt.Sprintf("%s[%d]", name, i), errs)
+		}
+	}
+}
+
+// validateField checks the field value with the rules provided.
+func validateField(v reflect.Value, name string, tag string, errs *FieldErrors) {
+	rules := strings.Split(tag, ",")
+
+	// The rules of the fields without value are checked only if they are
+	// required.
+	if v.IsZero() {
+		for _, rule := range rules {
+			if strings.TrimSpace(rule) == "required" {
+				*errs = append(*errs, &FieldError{
+					Field:   name,
+					Rule:    "required",
+					Message: "the field is required",
+				})
+			}
+		}
+		return
+	}
+
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" || rule == "required" {
+			continue
+		}
+
+		ruleName, param, _ := strings.Cut(rule, "=")
+
+		validatorsMu.RLock()
+		fn, ok := validators[ruleName]
+		validatorsMu.RUnlock()
+
+		var err error
+		if ok {
+			err = fn(v, param)
+		} else {
+			err = fmt.Errorf("unknown validation rule %q", ruleName)
+		}
+
+		if err != nil {
+			*errs = append(*errs, &FieldError{
+				Field:   name,
+				Rule:    ruleName,
+				Message: err.Error(),
+			})
+		}
+	}
+}
+
+// fieldName returns the name of the field for the clients.
+func fieldName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	for _, tag := range bindingTags {
+		if name, ok := field.Tag.Lookup(tag); ok && name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+func validateMin(v reflect.Value, param string) error {
+	return compare(v, param, func(value float64, limit float64) bool { return value >= limit },
+		"the length must be at least %s", "the value must be at least %s")
+}
+
+func validateMax(v reflect.Value, param string) error {
+	return compare(v, param, func(value float64, limit float64) bool { return value <= limit },
+		"the length must be at most %s", "the value must be at most %s")
+}
+
+func validateLen(v reflect.Value, param string) error {
+	return compare(v, param, func(value float64, limit float64) bool { return value == limit },
+		"the length must be %s", "the value must be %s")
+}
+
+// compare checks the length of the strings, slices and maps, or the value of
+// the numbers, with the limit provided.
+func compare(v reflect.Value, param string, check func(float64, float64) bool, lenMessage string, valueMessage string) error {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("invalid rule parameter %q", param)
+	}
+
+	var value float64
+	message := valueMessage
+
+	switch v.Kind() {
+	case reflect.String:
+		value = float64(len([]rune(v.String())))
+		message = lenMessage
+	case reflect.Slice, reflect.Array, reflect.Map:
+		value = float64(v.Len())
+		message = lenMessage
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	if !check(value, limit) {
+		return fmt.Errorf(message, param)
+	}
+
+	return nil
+}
+
+func validateOneOf(v reflect.Value, param string) error {
+	value := fmt.Sprint(v.Interface())
+	for _, option := range strings.Fields(param) {
+		if value == option {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("the value must be one of [%s]", strings.Join(strings.Fields(param), ", "))
+}
+
+func validateEmail(v reflect.Value, param string) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	addr, err := mail.ParseAddress(v.String())
+	if err != nil || addr.Address != v.String() {
+		return errors.New("the value must be a valid email address")
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package capo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type TestValidationData struct {
+	Name    string              `json:"name" validate:"required,min=3,max=8"`
+	Kind    string              `json:"kind" validate:"oneof=a b"`
+	Mail    string              `json:"mail" validate:"email"`
+	Age     int                 `json:"age" validate:"min=18"`
+	Tags    []string            `json:"tags" validate:"max=2"`
+	Child   *TestValidationData `json:"child"`
+	Comment string              `json:"comment" validate:"min=5"`
+}
+
+func TestValidateReturnsFieldErrors(t *testing.T) {
+	err := Validate(&TestValidationData{
+		Name:  "ab",
+		Kind:  "c",
+		Mail:  "invalid",
+		Age:   10,
+		Tags:  []string{"a", "b", "c"},
+		Child: &TestValidationData{Name: "valid"},
+	})
+	var serverErr *ServerError
+	require.ErrorAs(t, err, &serverErr)
+	require.Equal(t, ValidationErrorCode, serverErr.Code)
+
+	fieldErrs, ok := serverErr.Details.(FieldErrors)
+	require.True(t, ok)
+
+	result := []string{}
+	for _, fieldErr := range fieldErrs {
+		result = append(result, fieldErr.Field+":"+fieldErr.Rule)
+	}
+	require.Equal(t, []string{"name:min", "kind:oneof", "mail:email", "age:min", "tags:max"}, result)
+}
+
+func TestValidateChecksNestedStructs(t *testing.T) {
+	err := Validate(&TestValidationData{Name: "valid", Child: &TestValidationData{}})
+	var serverErr *ServerError
+	require.ErrorAs(t, err, &serverErr)
+	require.Equal(t, ValidationErrorCode, serverErr.Code)
+
+	fieldErrs := serverErr.Details.(FieldErrors)
+	require.Len(t, fieldErrs, 1)
+	require.Equal(t, "child.name", fieldErrs[0].Field)
+	require.Equal(t, "required", fieldErrs[0].Rule)
+}
+
+func TestValidatePassesValidEntity(t *testing.T) {
+	err := Validate(&TestValidationData{Name: "valid", Kind: "a", Mail: "test@test.com", Age: 20})
+	require.NoError(t, err)
+}
+
+func TestValidateSkipsRulesOfZeroValues(t *testing.T) {
+	type TestZeroData struct {
+		Count    int    `json:"count" validate:"min=1"`
+		Kind     string `json:"kind" validate:"oneof=a b"`
+		Required int    `json:"required" validate:"required,min=1"`
+	}
+
+	err := Validate(&TestZeroData{})
+	var serverErr *ServerError
+	require.ErrorAs(t, err, &serverErr)
+
+	fieldErrs := serverErr.Details.(FieldErrors)
+	require.Len(t, fieldErrs, 1)
+	require.Equal(t, "required", fieldErrs[0].Field)
+	require.Equal(t, "required", fieldErrs[0].Rule)
+
+	require.NoError(t, Validate(&TestZeroData{Required: 1}))
+}
+
+func TestValidateRunsCustomValidators(t *testing.T) {
+	RegisterValidator("uppercase", func(value reflect.Value, param string) error {
+		if strings.ToUpper(value.String()) != value.String() {
+			return errors.New("the value must be uppercase")
+		}
+		return nil
+	})
+
+	type TestCustomData struct {
+		Code string `json:"code" validate:"uppercase"`
+	}
+
+	require.NoError(t, Validate(&TestCustomData{Code: "ABC"}))
+
+	err := Validate(&TestCustomData{Code: "abc"})
+	fieldErrs := err.(*ServerError).Details.(FieldErrors)
+	require.Len(t, fieldErrs, 1)
+	require.Equal(t, "uppercase", fieldErrs[0].Rule)
+	require.Equal(t, "the value must be uppercase", fieldErrs[0].Message)
+}
+
+func TestContextReadValidWritesValidationErrors(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	serverHandler.Post("/", func(ctx *Context) error {
+		return ctx.ReadValid(&TestValidationData{})
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Post(s.URL, "application/json", strings.NewReader(`{"name":"ab"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body := struct {
+		Code    string        `json:"code"`
+		Details []*FieldError `json:"details"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	require.NoError(t, err)
+	require.Equal(t, ValidationErrorCode, body.Code)
+	require.Equal(t, []*FieldError{{Field: "name", Rule: "min", Message: "the length must be at least 3"}}, body.Details)
+}
+
+func TestContextReadValidRejectsEmptyBody(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	serverHandler.Post("/", func(ctx *Context) error {
+		err := ctx.ReadValid(&TestValidationData{})
+		require.ErrorIs(t, err, ErrEmptyBody)
+		return err
+	})
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Post(s.URL, "application/json", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	body := &ServerError{}
+	err = json.NewDecoder(res.Body).Decode(body)
+	require.NoError(t, err)
+	require.Equal(t, BadRequestErrorCode, body.Code)
+}
@@ -0,0 +1,279 @@
+package capo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tonygcs/capo/internal/mediatype"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// WebSocket close codes.
+const (
+	WSCloseNormal          = websocket.CloseNormalClosure
+	WSCloseGoingAway       = websocket.CloseGoingAway
+	WSCloseUnsupportedData = websocket.CloseUnsupportedData
+	WSClosePolicyViolation = websocket.ClosePolicyViolation
+	WSCloseMessageTooBig   = websocket.CloseMessageTooBig
+	WSCloseInternalError   = websocket.CloseInternalServerErr
+)
+
+// defaultPingInterval is the default time between the ping messages.
+const defaultPingInterval = 30 * time.Second
+
+// WSHandler is the handler of a WebSocket connection. The connection is closed
+// when it returns. If it returns a "WSCloseError", the connection is closed
+// with its code; otherwise, it is closed with the normal closure code or the
+// internal error code if it returns an error.
+type WSHandler func(ctx *Context, conn *WSConn) error
+
+// WSCloseError is the error of a closed WebSocket connection.
+type WSCloseError struct {
+	Code   int
+	Reason string
+}
+
+// NewWSCloseError creates a new instance of WebSocket close error.
+func NewWSCloseError(code int, reason string) *WSCloseError {
+	return &WSCloseError{Code: code, Reason: reason}
+}
+
+func (e *WSCloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket closed with code %d", e.Code)
+	}
+
+	return fmt.Sprintf("websocket closed with code %d - %s", e.Code, e.Reason)
+}
+
+// WSOption is an option to configure a WebSocket route.
+type WSOption func(*wsConfig)
+
+type wsConfig struct {
+	upgrader     websocket.Upgrader
+	pingInterval time.Duration
+	readLimit    int64
+}
+
+// WithPingInterval sets the time between the ping messages that keep the
+// connection alive. The connection is closed if the client does not answer
+// with a pong message before the next ping. Zero disables them. The default
+// value is 30 seconds.
+func WithPingInterval(d time.Duration) WSOption {
+	return func(c *wsConfig) { c.pingInterval = d }
+}
+
+// WithReadLimit sets the maximum size in bytes of the received messages.
+func WithReadLimit(limit int64) WSOption {
+	return func(c *wsConfig) { c.readLimit = limit }
+}
+
+// WithCheckOrigin sets the function that checks the request origin. By
+// default, the requests with an origin different from the host are rejected.
+func WithCheckOrigin(fn func(r *http.Request) bool) WSOption {
+	return func(c *wsConfig) { c.upgrader.CheckOrigin = fn }
+}
+
+// WithSubprotocols sets the supported subprotocols in order of preference.
+func WithSubprotocols(protocols ...string) WSOption {
+	return func(c *wsConfig) { c.upgrader.Subprotocols = protocols }
+}
+
+// WebSocket handles the WebSocket connections in the path provided. The before
+// middlewares run before the connection is upgraded, so they can reject it.
+func (g *group) WebSocket(relativePath string, handler WSHandler, opts ...WSOption) {
+	g.Handle(http.MethodGet, relativePath, websocketHandler(handler, opts...))
+}
+
+// websocketHandler returns the request handler that upgrades the connection
+// and runs the WebSocket handler.
+func websocketHandler(handler WSHandler, opts ...WSOption) Handler {
+	config := &wsConfig{pingInterval: defaultPingInterval}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(ctx *Context) error {
+		// The connection messages use the response marshaler.
+		m, err := ctx.ResponseMarshaler()
+		if err != nil {
+			return err
+		}
+
+		// The upgrade errors are written by the error handling middlewares.
+		var upgradeErr error
+		upgrader := config.upgrader
+		upgrader.Error = func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			upgradeErr = NewServerError(BadRequestErrorCode, reason).WithStatus(status)
+		}
+
+		// The response headers set by the before middlewares are sent in the
+		// upgrade response.
+		header := http.Header{}
+		for key, value := range ctx.headers {
+			header.Set(key, value)
+		}
+
+		conn, err := upgrader.Upgrade(ctx.w, ctx.r, header)
+		if err != nil {
+			if upgradeErr != nil {
+				return upgradeErr
+			}
+			return NewServerError(BadRequestErrorCode, err)
+		}
+
+		wsConn := newWSConn(conn, m)
+		if config.readLimit > 0 {
+			conn.SetReadLimit(config.readLimit)
+		}
+
+		stop := wsConn.keepAlive(config.pingInterval)
+		err = handler(ctx, wsConn)
+		stop()
+
+		return wsConn.closeWith(err)
+	}
+}
+
+// WSConn is a WebSocket connection. The messages are marshaled with the
+// response marshaler of the upgrade request. It is safe to write messages from
+// different goroutines, but only one goroutine can read messages.
+type WSConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+	m    marshaler.Marshaler
+}
+
+func newWSConn(conn *websocket.Conn, m marshaler.Marshaler) *WSConn {
+	return &WSConn{conn: conn, m: m}
+}
+
+// Conn returns the underlying WebSocket connection.
+func (c *WSConn) Conn() *websocket.Conn {
+	return c.conn
+}
+
+// Marshaler returns the marshaler of the connection messages.
+func (c *WSConn) Marshaler() marshaler.Marshaler {
+	return c.m
+}
+
+// ReadMessage reads the next message and unmarshals it in the entity provided.
+// It returns a "WSCloseError" if the client closes the connection.
+func (c *WSConn) ReadMessage(entity any) error {
+	_, data, err := c.conn.ReadMessage()
+	if err != nil {
+		return wsError(err)
+	}
+
+	return c.m.Unmarshal(data, entity)
+}
+
+// WriteMessage marshals the entity provided and sends it. The message is a
+// text message if the marshaler format is text, e.g. JSON or XML; otherwise,
+// it is a binary message.
+func (c *WSConn) WriteMessage(entity any) error {
+	data, err := c.m.Marshal(entity)
+	if err != nil {
+		return err
+	}
+
+	messageType := websocket.BinaryMessage
+	if mediatype.IsText(c.m.ContentTypeHeader()) {
+		messageType = websocket.TextMessage
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return wsError(c.conn.WriteMessage(messageType, data))
+}
+
+// Close sends the close message with the code and reason provided and closes
+// the connection.
+func (c *WSConn) Close(code int, reason string) error {
+	message := websocket.FormatCloseMessage(code, reason)
+	err := c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+	if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+		c.conn.Close()
+		return err
+	}
+
+	return c.conn.Close()
+}
+
+// closeWith closes the connection according to the handler error. The
+// connections closed by the client are not an error.
+func (c *WSConn) closeWith(err error) error {
+	var closeErr *WSCloseError
+	switch {
+	case err == nil:
+		c.Close(WSCloseNormal, "")
+		return nil
+
+	case errors.As(err, &closeErr):
+		c.Close(closeErr.Code, closeErr.Reason)
+		if closeErr.Code == WSCloseNormal || closeErr.Code == WSCloseGoingAway {
+			return nil
+		}
+		return err
+
+	default:
+		c.Close(WSCloseInternalError, "")
+		return err
+	}
+}
+
+// keepAlive sends ping messages until the returned function is called. The
+// read deadline is extended every time a pong message is received.
+func (c *WSConn) keepAlive(interval time.Duration) func() {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	c.conn.SetReadDeadline(time.Now().Add(2 * interval))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(2 * interval))
+	})
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval))
+				if err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// wsError converts the WebSocket close errors in a "WSCloseError".
+func wsError(err error) error {
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		return NewWSCloseError(closeErr.Code, closeErr.Text)
+	}
+
+	return err
+}
@@ -0,0 +1,183 @@
+package capo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"github.com/tonygcs/capo/marshaler"
+)
+
+// dialTestWebSocket connects to the WebSocket route of the server provided.
+func dialTestWebSocket(t *testing.T, serverHandler *Server, path string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	s := httptest.NewServer(serverHandler)
+	t.Cleanup(s.Close)
+
+	conn, res, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http")+path, header)
+	if err == nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+
+	return conn, res, err
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	serverHandler := New()
+
+	closed := make(chan error, 1)
+	serverHandler.UseAfterAlways(func(ctx *Context) {
+		closed <- ctx.Err()
+	})
+	serverHandler.Group("/api").WebSocket("/echo/{name}", func(ctx *Context, conn *WSConn) error {
+		for {
+			msg := &TestData{}
+			if err := conn.ReadMessage(msg); err != nil {
+				return err
+			}
+
+			msg.Message = ctx.Param("name") + " " + msg.Message
+			if err := conn.WriteMessage(msg); err != nil {
+				return err
+			}
+		}
+	})
+
+	conn, res, err := dialTestWebSocket(t, serverHandler, "/api/echo/test", nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+	require.NoError(t, conn.WriteJSON(&TestData{Message: "hello"}))
+
+	msgType, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, websocket.TextMessage, msgType)
+	require.JSONEq(t, `{"msg":"test hello"}`, string(data))
+
+	// The connection closed by the client is not an error.
+	require.NoError(t, conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(WSCloseNormal, "")))
+
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "the connection is not closed")
+	}
+}
+
+func TestWebSocketBeforeMiddlewaresRejectUpgrade(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+
+	g := serverHandler.Group("/ws")
+	g.UseBefore(func(ctx *Context) error {
+		if ctx.Request().Header.Get("Authorization") == "" {
+			return NewServerError(UnauthorizedErrorCode, errors.New("missing token"))
+		}
+		return nil
+	})
+	g.WebSocket("", func(ctx *Context, conn *WSConn) error { return nil })
+
+	_, res, err := dialTestWebSocket(t, serverHandler, "/ws", nil)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	conn, _, err := dialTestWebSocket(t, serverHandler, "/ws", http.Header{"Authorization": {"token"}})
+	require.NoError(t, err)
+
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, WSCloseNormal), err)
+}
+
+func TestWebSocketInvalidUpgradeRequest(t *testing.T) {
+	serverHandler := New()
+	serverHandler.UseAfterAlways(ErrorHandling)
+	serverHandler.WebSocket("/ws", func(ctx *Context, conn *WSConn) error { return nil })
+
+	s := httptest.NewServer(serverHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL + "/ws")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+}
+
+func TestWebSocketCloseCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "normal", err: nil, code: WSCloseNormal},
+		{name: "close error", err: NewWSCloseError(WSClosePolicyViolation, "forbidden"), code: WSClosePolicyViolation},
+		{name: "internal error", err: errors.New("unexpected"), code: WSCloseInternalError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serverHandler := New()
+			serverHandler.WebSocket("/ws", func(ctx *Context, conn *WSConn) error {
+				return test.err
+			})
+
+			conn, _, err := dialTestWebSocket(t, serverHandler, "/ws", nil)
+			require.NoError(t, err)
+
+			_, _, err = conn.ReadMessage()
+			require.True(t, websocket.IsCloseError(err, test.code), err)
+		})
+	}
+}
+
+func TestWebSocketMarshaler(t *testing.T) {
+	serverHandler := New()
+	serverHandler.SetMarshalers(&marshaler.JSONMarshaler{}, &marshaler.MsgPackMarshaler{})
+	serverHandler.WebSocket("/ws", func(ctx *Context, conn *WSConn) error {
+		return conn.WriteMessage(&TestData{Message: "binary"})
+	})
+
+	conn, _, err := dialTestWebSocket(t, serverHandler, "/ws", http.Header{"Accept": {"application/msgpack"}})
+	require.NoError(t, err)
+
+	msgType, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, websocket.BinaryMessage, msgType)
+
+	msg := &TestData{}
+	require.NoError(t, (&marshaler.MsgPackMarshaler{}).Unmarshal(data, msg))
+	require.Equal(t, "binary", msg.Message)
+}
+
+func TestWebSocketPing(t *testing.T) {
+	serverHandler := New()
+	serverHandler.WebSocket("/ws", func(ctx *Context, conn *WSConn) error {
+		msg := &TestData{}
+		return conn.ReadMessage(msg)
+	}, WithPingInterval(10*time.Millisecond))
+
+	conn, _, err := dialTestWebSocket(t, serverHandler, "/ws", nil)
+	require.NoError(t, err)
+
+	pings := make(chan struct{}, 10)
+	conn.SetPingHandler(func(data string) error {
+		pings <- struct{}{}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+
+	// The pings are handled while reading.
+	go conn.ReadMessage()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-pings:
+		case <-time.After(time.Second):
+			require.Fail(t, "the server does not send pings")
+		}
+	}
+}